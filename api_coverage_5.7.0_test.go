@@ -41,47 +41,48 @@ func Test_57_StickerSet_IsVideo_JSON(t *testing.T) {
 
 func Test_57_CreateNewStickerSet_WebM_FileKey(t *testing.T) {
 	c := NewStickerSetConfig{
-		UserID:      1,
-		Name:        "pack_by_bot",
-		Title:       "Pack",
-		Emojis:      "😀",
-		WebMSticker: FilePath("tests/1347045309.webm"),
+		UserID:   1,
+		Name:     "pack_by_bot",
+		Title:    "Pack",
+		Stickers: []InputSticker{NewInputSticker(FilePath("tests/1347045309.webm"), StickerFormatVideo, "😀")},
 	}
 	files := c.files()
 	if len(files) != 1 {
 		t.Fatalf("files len=%d, want 1", len(files))
 	}
-	if files[0].Name != "webm_sticker" {
-		t.Fatalf("file key=%q, want webm_sticker", files[0].Name)
+	if files[0].Name != "sticker-0" {
+		t.Fatalf("file key=%q, want sticker-0", files[0].Name)
 	}
 }
 
 func Test_57_CreateNewStickerSet_TGS_FileKey(t *testing.T) {
 	c := NewStickerSetConfig{
-		UserID:     1,
-		Name:       "pack_by_bot",
-		Title:      "Pack",
-		Emojis:     "😀",
-		TGSSticker: FilePath("tests/1083673963.tgs"), // <-- TGSSticker, не WebMSticker
+		UserID:   1,
+		Name:     "pack_by_bot",
+		Title:    "Pack",
+		Stickers: []InputSticker{NewInputSticker(FilePath("tests/1083673963.tgs"), StickerFormatAnimated, "😀")},
 	}
-	files := c.files()
-	if len(files) != 1 {
-		t.Fatalf("files len=%d, want 1", len(files))
+	params, err := c.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(params["stickers"], `"format":"animated"`) {
+		t.Fatalf("stickers=%s, want animated format", params["stickers"])
 	}
-	if files[0].Name != "tgs_sticker" {
-		t.Fatalf("file key=%q, want tgs_sticker", files[0].Name)
+	files := c.files()
+	if len(files) != 1 || files[0].Name != "sticker-0" {
+		t.Fatalf("bad files: %#v", files)
 	}
 }
 
 func Test_57_AddSticker_WebM_FileKey(t *testing.T) {
 	c := AddStickerConfig{
-		UserID:      1,
-		Name:        "pack_by_bot",
-		Emojis:      "😀",
-		WebMSticker: FilePath("tests/1347045309.webm"),
+		UserID:  1,
+		Name:    "pack_by_bot",
+		Sticker: NewInputSticker(FilePath("tests/1347045309.webm"), StickerFormatVideo, "😀"),
 	}
 	files := c.files()
-	if len(files) != 1 || files[0].Name != "webm_sticker" {
+	if len(files) != 1 || files[0].Name != "sticker-0" {
 		t.Fatalf("bad files: %#v", files)
 	}
 }
@@ -95,13 +96,12 @@ func Test_57_CreateWebMStickerAndSend(t *testing.T) {
 	name := fmt.Sprintf("api57_%d_by_%s", time.Now().Unix(), username)
 	title := fmt.Sprintf("API57 %d", time.Now().Unix())
 
-	// 1) createNewStickerSet (webm_sticker)
+	// 1) createNewStickerSet (video sticker)
 	create := NewStickerSetConfig{
-		UserID:      ChatID,
-		Name:        name,
-		Title:       title,
-		Emojis:      "😀",
-		WebMSticker: FilePath("tests/1347045309.webm"),
+		UserID:   ChatID,
+		Name:     name,
+		Title:    title,
+		Stickers: []InputSticker{NewInputSticker(FilePath("tests/1347045309.webm"), StickerFormatVideo, "😀")},
 	}
 	if _, err := bot.Request(create); err != nil {
 		t.Fatalf("createNewStickerSet: %v", err)
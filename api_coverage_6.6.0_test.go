@@ -0,0 +1,230 @@
+package tgbotapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func Test66_Sticker_Thumbnail_JSON(t *testing.T) {
+	raw := []byte(`{"thumbnail":{"file_id":"T1"},"stickers":[]}`)
+
+	var s Sticker
+	if err := json.Unmarshal(raw, &s); err != nil {
+		t.Fatal(err)
+	}
+	if s.Thumbnail == nil || s.Thumbnail.FileID != "T1" {
+		t.Fatalf("sticker thumbnail not parsed: %+v", s.Thumbnail)
+	}
+
+	var ss StickerSet
+	if err := json.Unmarshal(raw, &ss); err != nil {
+		t.Fatal(err)
+	}
+	if ss.Thumbnail == nil || ss.Thumbnail.FileID != "T1" {
+		t.Fatalf("sticker set thumbnail not parsed: %+v", ss.Thumbnail)
+	}
+}
+
+func Test66_CreateNewStickerSet_StickerType(t *testing.T) {
+	c := NewStickerSetConfig{
+		UserID:      1,
+		Name:        "pack_by_bot",
+		Title:       "Pack",
+		Stickers:    []InputSticker{NewInputSticker(FileID("F1"), StickerFormatStatic, "😀")},
+		StickerType: StickerTypeCustomEmoji,
+	}
+
+	params, err := c.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["sticker_type"] != "custom_emoji" {
+		t.Fatalf("sticker_type=%q, want custom_emoji", params["sticker_type"])
+	}
+}
+
+func Test66_CreateNewStickerSet_Validation(t *testing.T) {
+	for name, stickers := range map[string][]InputSticker{
+		"no stickers": nil,
+		"no emoji":    {{Sticker: FileID("F1"), Format: StickerFormatStatic}},
+		"no format":   {{Sticker: FileID("F1"), EmojiList: []string{"😀"}}},
+		"no file":     {{Format: StickerFormatStatic, EmojiList: []string{"😀"}}},
+	} {
+		c := NewStickerSetConfig{UserID: 1, Name: "pack_by_bot", Title: "Pack", Stickers: stickers}
+		if _, err := c.params(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func Test66_CreateNewStickerSet_OneMultipartRequest(t *testing.T) {
+	var (
+		stickers string
+		parts    []string
+	)
+
+	bot := newLocalBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Error(err)
+		}
+		stickers = r.FormValue("stickers")
+		for name := range r.MultipartForm.File {
+			parts = append(parts, name)
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+	})
+
+	c := NewStickerSetConfig{
+		UserID: 1,
+		Name:   "pack_by_bot",
+		Title:  "Pack",
+		Stickers: []InputSticker{
+			NewInputSticker(FileBytes{Name: "a.png", Bytes: []byte("a")}, StickerFormatStatic, "😀"),
+			NewInputSticker(FileID("F1"), StickerFormatStatic, "😎"),
+			NewInputSticker(FileBytes{Name: "c.webm", Bytes: []byte("c")}, StickerFormatVideo, "🙂"),
+		},
+	}
+
+	if _, err := bot.Request(c); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(parts) != 2 {
+		t.Fatalf("uploaded parts %v, want sticker-0 and sticker-2", parts)
+	}
+
+	var sent []struct {
+		Sticker string `json:"sticker"`
+		Format  string `json:"format"`
+	}
+	if err := json.Unmarshal([]byte(stickers), &sent); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"attach://sticker-0", "F1", "attach://sticker-2"}
+	for i, s := range sent {
+		if s.Sticker != want[i] {
+			t.Errorf("sticker %d=%q, want %q", i, s.Sticker, want[i])
+		}
+	}
+}
+
+func Test66_StickerEditConfigs(t *testing.T) {
+	emoji, _ := SetStickerEmojiListConfig{Sticker: "S", EmojiList: []string{"😀", "😎"}}.params()
+	if emoji["emoji_list"] != `["😀","😎"]` {
+		t.Fatalf("emoji_list=%s", emoji["emoji_list"])
+	}
+
+	keywords, _ := SetStickerKeywordsConfig{Sticker: "S", Keywords: []string{"smile"}}.params()
+	if keywords["keywords"] != `["smile"]` {
+		t.Fatalf("keywords=%s", keywords["keywords"])
+	}
+
+	replace := ReplaceStickerInSetConfig{
+		UserID:     1,
+		Name:       "pack_by_bot",
+		OldSticker: "OLD",
+		Sticker:    NewInputSticker(FilePath("tests/image.jpg"), StickerFormatStatic, "😀"),
+	}
+	params, err := replace.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["old_sticker"] != "OLD" || !strings.Contains(params["sticker"], `"attach://sticker-0"`) {
+		t.Fatalf("unexpected params %v", params)
+	}
+
+	thumb := SetStickerSetThumbnailConfig{Name: "pack_by_bot", UserID: 1, Format: StickerFormatStatic}
+	if files := thumb.files(); len(files) != 0 {
+		t.Fatalf("removing the thumbnail must not send files: %v", files)
+	}
+}
+
+func Test66_StickerFormat_Required(t *testing.T) {
+	for _, c := range []Chattable{
+		UploadStickerConfig{UserID: 1, Sticker: FileID("F1")},
+		SetStickerSetThumbnailConfig{Name: "pack_by_bot", UserID: 1, Thumbnail: FileID("T1")},
+	} {
+		if _, err := c.params(); err == nil {
+			t.Errorf("%s: expected an error without a format", c.method())
+		}
+	}
+}
+
+func Test66_DeprecatedStickerFields(t *testing.T) {
+	create := NewStickerSetConfig{
+		UserID:       1,
+		Name:         "pack_by_bot",
+		Title:        "Pack",
+		Emojis:       "😀👍",
+		WebMSticker:  FilePath("tests/1347045309.webm"),
+		Sticker_type: StickerTypeMask,
+	}
+	params, err := create.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["stickers"] != `[{"sticker":"attach://sticker-0","format":"video","emoji_list":["😀","👍"]}]` {
+		t.Fatalf("stickers=%s", params["stickers"])
+	}
+	if params["sticker_type"] != "mask" {
+		t.Fatalf("sticker_type=%q, want mask", params["sticker_type"])
+	}
+	if files := create.files(); len(files) != 1 || files[0].Name != "sticker-0" {
+		t.Fatalf("bad files: %#v", files)
+	}
+
+	create.PNGSticker = FileID("F1")
+	if _, err := create.params(); err == nil {
+		t.Fatal("expected an error with two sticker files")
+	}
+
+	add := AddStickerConfig{UserID: 1, Name: "pack_by_bot", Emojis: "😀", TGSSticker: FileID("F1")}
+	params, err = add.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["sticker"] != `{"sticker":"F1","format":"animated","emoji_list":["😀"]}` {
+		t.Fatalf("sticker=%s", params["sticker"])
+	}
+
+	upload := UploadStickerConfig{UserID: 1, PNGSticker: FilePath("tests/image.jpg")}
+	params, err = upload.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["sticker_format"] != "static" {
+		t.Fatalf("sticker_format=%q, want static", params["sticker_format"])
+	}
+	if files := upload.files(); len(files) != 1 || files[0].Name != "sticker" || files[0].Data != FilePath("tests/image.jpg") {
+		t.Fatalf("bad files: %#v", files)
+	}
+
+	thumb := SetStickerSetThumbConfig{Name: "pack_by_bot", UserID: 1, Thumb: FilePath("tests/1083673963.tgs")}
+	params, err = thumb.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if thumb.method() != "setStickerSetThumbnail" || params["format"] != "animated" {
+		t.Fatalf("method=%s format=%q", thumb.method(), params["format"])
+	}
+	if files := thumb.files(); len(files) != 1 || files[0].Name != "thumbnail" {
+		t.Fatalf("bad files: %#v", files)
+	}
+}
+
+func Test66_SplitEmoji(t *testing.T) {
+	for s, want := range map[string][]string{
+		"😀":         {"😀"},
+		"😀👍":        {"😀", "👍"},
+		"👍🏽❤️":      {"👍🏽", "❤️"},
+		"👨‍👩‍👧🇺🇦🇫🇷": {"👨‍👩‍👧", "🇺🇦", "🇫🇷"},
+		"1️⃣2️⃣":    {"1️⃣", "2️⃣"},
+	} {
+		if got := splitEmoji(s); strings.Join(got, "|") != strings.Join(want, "|") {
+			t.Errorf("splitEmoji(%q) = %q, want %q", s, got, want)
+		}
+	}
+}
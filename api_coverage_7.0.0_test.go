@@ -0,0 +1,208 @@
+package tgbotapi
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func Test70_MessageReaction_Update(t *testing.T) {
+	raw := []byte(`{
+		"update_id": 1,
+		"message_reaction": {
+			"chat": {"id": -100, "type": "supergroup"},
+			"message_id": 7,
+			"user": {"id": 42, "first_name": "Ann"},
+			"date": 1700000000,
+			"old_reaction": [{"type": "emoji", "emoji": "👍"}, {"type": "custom_emoji", "custom_emoji_id": "CE"}],
+			"new_reaction": [{"type": "emoji", "emoji": "👍"}, {"type": "emoji", "emoji": "🔥"}]
+		}
+	}`)
+
+	var u Update
+	if err := json.Unmarshal(raw, &u); err != nil {
+		t.Fatal(err)
+	}
+
+	if u.UpdateType() != UpdateTypeMessageReaction {
+		t.Fatalf("update type=%q", u.UpdateType())
+	}
+	if chat := u.FromChat(); chat == nil || chat.ID != -100 {
+		t.Fatalf("unexpected chat %+v", chat)
+	}
+	if user := u.SentFrom(); user == nil || user.ID != 42 {
+		t.Fatalf("unexpected user %+v", user)
+	}
+
+	added := u.MessageReaction.Added()
+	if len(added) != 1 || added[0] != NewReactionEmoji("🔥") {
+		t.Fatalf("added=%v", added)
+	}
+	removed := u.MessageReaction.Removed()
+	if len(removed) != 1 || removed[0] != NewReactionCustomEmoji("CE") {
+		t.Fatalf("removed=%v", removed)
+	}
+
+	if !IsReactionAdded()(u) || !IsReactionAdded(NewReactionEmoji("🔥"))(u) {
+		t.Fatal("IsReactionAdded did not match the added reaction")
+	}
+	if IsReactionAdded(NewReactionEmoji("👍"))(u) {
+		t.Fatal("IsReactionAdded matched a reaction that was kept")
+	}
+	if !IsReactionRemoved(NewReactionCustomEmoji("CE"))(u) {
+		t.Fatal("IsReactionRemoved did not match the removed reaction")
+	}
+}
+
+func Test70_MessageReactionCount_Update(t *testing.T) {
+	raw := []byte(`{
+		"update_id": 2,
+		"message_reaction_count": {
+			"chat": {"id": -100, "type": "channel"},
+			"message_id": 7,
+			"date": 1700000000,
+			"reactions": [{"type": {"type": "paid"}, "total_count": 3}]
+		}
+	}`)
+
+	var u Update
+	if err := json.Unmarshal(raw, &u); err != nil {
+		t.Fatal(err)
+	}
+
+	if u.UpdateType() != UpdateTypeMessageReactionCount {
+		t.Fatalf("update type=%q", u.UpdateType())
+	}
+	reactions := u.MessageReactionCount.Reactions
+	if len(reactions) != 1 || reactions[0].Type != NewReactionPaid() || reactions[0].TotalCount != 3 {
+		t.Fatalf("unexpected reactions %+v", reactions)
+	}
+	if IsReactionAdded()(u) {
+		t.Fatal("IsReactionAdded matched an anonymous reaction count")
+	}
+}
+
+func Test70_Chat_AvailableReactions(t *testing.T) {
+	var c Chat
+	if err := json.Unmarshal([]byte(`{"id":1,"available_reactions":[{"type":"emoji","emoji":"❤"}]}`), &c); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.AvailableReactions) != 1 || c.AvailableReactions[0].Emoji != "❤" {
+		t.Fatalf("unexpected available reactions %+v", c.AvailableReactions)
+	}
+}
+
+func Test70_SetMessageReaction_Params(t *testing.T) {
+	c := NewSetMessageReaction(int64(-100), 7, NewReactionEmoji("👍"))
+	c.IsBig = true
+
+	params, err := c.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["chat_id"] != "-100" || params["message_id"] != "7" || params["is_big"] != "true" {
+		t.Fatalf("unexpected params %v", params)
+	}
+	if params["reaction"] != `[{"type":"emoji","emoji":"👍"}]` {
+		t.Fatalf("reaction=%s", params["reaction"])
+	}
+
+	params, _ = NewSetMessageReaction(int64(-100), 7).params()
+	if params["reaction"] != `[]` {
+		t.Fatalf("removing reactions must send an empty list, got %q", params["reaction"])
+	}
+}
+
+func Test70_ReplyParameters_Params(t *testing.T) {
+	legacy := NewMessage(int64(1), "hi")
+	legacy.ReplyToMessageID = 5
+	legacy.AllowSendingWithoutReply = true
+
+	params, err := legacy.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["reply_parameters"] != `{"message_id":5,"allow_sending_without_reply":true}` {
+		t.Fatalf("reply_parameters=%s", params["reply_parameters"])
+	}
+
+	quote := NewMessage(int64(1), "hi")
+	quote.ReplyToMessageID = 5
+	quote.ReplyParameters = &ReplyParameters{MessageID: 9, ChatID: -100, Quote: "part", QuotePosition: 3}
+
+	params, err = quote.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["reply_parameters"] != `{"message_id":9,"chat_id":-100,"quote":"part","quote_position":3}` {
+		t.Fatalf("reply_parameters=%s", params["reply_parameters"])
+	}
+
+	group := NewMediaGroup(int64(1), []InputMedia{
+		NewInputMediaPhoto(FileID("A")),
+		NewInputMediaPhoto(FileID("B")),
+	})
+	group.ReplyToMessageID = 5
+
+	params, err = group.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["reply_parameters"] != `{"message_id":5}` {
+		t.Fatalf("media group reply_parameters=%s", params["reply_parameters"])
+	}
+}
+
+func Test70_LinkPreviewOptions_Params(t *testing.T) {
+	legacy := NewMessage(int64(1), "https://example.com")
+	legacy.DisableWebPagePreview = true
+
+	params, _ := legacy.params()
+	if params["link_preview_options"] != `{"is_disabled":true}` {
+		t.Fatalf("link_preview_options=%s", params["link_preview_options"])
+	}
+
+	edit := NewEditMessageText(1, 2, "https://example.com")
+	edit.LinkPreviewOptions = &LinkPreviewOptions{URL: "https://example.org", PreferLargeMedia: true, ShowAboveText: true}
+
+	params, _ = edit.params()
+	if params["link_preview_options"] != `{"url":"https://example.org","prefer_large_media":true,"show_above_text":true}` {
+		t.Fatalf("link_preview_options=%s", params["link_preview_options"])
+	}
+
+	if params, _ := NewMessage(int64(1), "hi").params(); params["link_preview_options"] != "" {
+		t.Fatalf("unexpected link_preview_options %s", params["link_preview_options"])
+	}
+}
+
+func Test70_Message_ExternalReply_Quote(t *testing.T) {
+	raw := []byte(`{
+		"message_id": 3,
+		"chat": {"id": 1, "type": "private"},
+		"date": 1700000000,
+		"text": "see https://example.com",
+		"external_reply": {
+			"origin": {"type": "channel", "date": 1690000000, "chat": {"id": -100, "type": "channel"}, "message_id": 44},
+			"chat": {"id": -100, "type": "channel"},
+			"message_id": 44,
+			"photo": [{"file_id": "P", "file_unique_id": "U", "width": 1, "height": 1}]
+		},
+		"quote": {"text": "part", "position": 3, "is_manual": true},
+		"link_preview_options": {"url": "https://example.com", "prefer_small_media": true}
+	}`)
+
+	var m Message
+	if err := json.Unmarshal(raw, &m); err != nil {
+		t.Fatal(err)
+	}
+
+	reply := m.ExternalReply
+	if reply == nil || reply.Origin.Type != "channel" || reply.Origin.MessageID != 44 || reply.Chat.ID != -100 || len(reply.Photo) != 1 {
+		t.Fatalf("unexpected external reply %+v", reply)
+	}
+	if m.Quote == nil || m.Quote.Text != "part" || m.Quote.Position != 3 || !m.Quote.IsManual {
+		t.Fatalf("unexpected quote %+v", m.Quote)
+	}
+	if m.LinkPreviewOptions == nil || !m.LinkPreviewOptions.PreferSmallMedia {
+		t.Fatalf("unexpected link preview options %+v", m.LinkPreviewOptions)
+	}
+}
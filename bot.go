@@ -3,6 +3,7 @@
 package tgbotapi
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,7 +12,8 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
-	"time"
+	"sync"
+	"sync/atomic"
 )
 
 // HTTPClient is the type needed for the bot to perform HTTP requests.
@@ -25,11 +27,64 @@ type BotAPI struct {
 	Debug  bool   `json:"debug"`
 	Buffer int    `json:"buffer"`
 
-	Self            User       `json:"-"`
-	Client          HTTPClient `json:"-"`
-	shutdownChannel chan interface{}
-
-	apiEndpoint string
+	Self   User       `json:"-"`
+	Client HTTPClient `json:"-"`
+
+	// RetryPolicy enables retrying failed requests. Requests are not
+	// retried if it is nil.
+	RetryPolicy *RetryPolicy `json:"-"`
+	// RateLimiter paces outgoing requests if it is not nil.
+	RateLimiter RateLimiter `json:"-"`
+
+	// FollowChatMigrations resends requests that failed because the group
+	// was upgraded to a supergroup to the new supergroup.
+	FollowChatMigrations bool `json:"-"`
+	// OnChatMigrated is called with the old and new chat IDs when a request
+	// fails because the group was upgraded to a supergroup.
+	OnChatMigrated func(oldChatID, newChatID int64) `json:"-"`
+
+	// FileIDCache, if not nil, remembers the file IDs of uploaded files so
+	// that sending the same file again with the same send method doesn't
+	// upload it.
+	FileIDCache FileIDCache `json:"-"`
+
+	// OffsetStore, if not nil, persists the offset of GetUpdatesChan so
+	// polling resumes after a restart. Updates must then be acknowledged
+	// with AckUpdate once handled.
+	OffsetStore OffsetStore `json:"-"`
+
+	// UpdateIDStore, if not nil, records handled updates so that updates
+	// delivered twice by polling or webhooks are dropped. Updates received
+	// from a channel are recorded once acknowledged with AckUpdate, until
+	// then they are only known to this BotAPI. Updates that are never
+	// acknowledged are recorded after 10 minutes, when the next update
+	// arrives.
+	UpdateIDStore UpdateIDStore `json:"-"`
+	// OnDuplicateUpdate is called with every update dropped as a duplicate.
+	OnDuplicateUpdate func(update Update) `json:"-"`
+
+	// UploadChatActions shows a chat action such as ChatUploadVideo in the
+	// chat while files are uploaded to it.
+	UploadChatActions bool `json:"-"`
+
+	apiEndpoint  string
+	fileEndpoint string
+	localServer  bool
+
+	pollMu        sync.Mutex
+	poller        *updatesPoller
+	updatesOffset atomic.Int64
+	acks          atomic.Pointer[updateAcks]
+
+	pendingUpdates pendingUpdates
+}
+
+// updatesPoller tracks the goroutines started by GetUpdatesChanContext
+// until the next call to StopReceivingUpdates.
+type updatesPoller struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
 }
 
 // NewBotAPI creates a new BotAPI instance.
@@ -53,10 +108,9 @@ func NewBotAPIWithAPIEndpoint(token, apiEndpoint string) (*BotAPI, error) {
 // It requires a token, provided by @BotFather on Telegram and API endpoint.
 func NewBotAPIWithClient(token, apiEndpoint string, client HTTPClient) (*BotAPI, error) {
 	bot := &BotAPI{
-		Token:           token,
-		Client:          client,
-		Buffer:          100,
-		shutdownChannel: make(chan interface{}),
+		Token:  token,
+		Client: client,
+		Buffer: 100,
 
 		apiEndpoint: apiEndpoint,
 	}
@@ -76,6 +130,14 @@ func (bot *BotAPI) SetAPIEndpoint(apiEndpoint string) {
 	bot.apiEndpoint = apiEndpoint
 }
 
+// SetFileEndpoint changes the endpoint files are downloaded from.
+//
+// By default it is derived from the API endpoint when that ends with
+// "/bot%s/%s", and FileEndpoint otherwise.
+func (bot *BotAPI) SetFileEndpoint(fileEndpoint string) {
+	bot.fileEndpoint = fileEndpoint
+}
+
 func buildParams(in Params) url.Values {
 	if in == nil {
 		return url.Values{}
@@ -92,6 +154,34 @@ func buildParams(in Params) url.Values {
 
 // MakeRequest makes a request to a specific endpoint with our token.
 func (bot *BotAPI) MakeRequest(endpoint string, params Params) (*APIResponse, error) {
+	return bot.MakeRequestContext(context.Background(), endpoint, params)
+}
+
+// MakeRequestContext makes a request to a specific endpoint with our token.
+//
+// The request is aborted when ctx is cancelled or its deadline expires.
+// Failed requests are retried according to bot.RetryPolicy.
+func (bot *BotAPI) MakeRequestContext(ctx context.Context, endpoint string, params Params) (*APIResponse, error) {
+	request := func(params Params) (*APIResponse, error) {
+		return bot.withRetry(ctx, endpoint, func() (*APIResponse, error) {
+			return bot.makeRequest(ctx, endpoint, params)
+		})
+	}
+
+	resp, err := request(params)
+	if migrated, ok := bot.chatMigrated(params, err); ok {
+		return request(migrated)
+	}
+
+	return resp, err
+}
+
+// makeRequest makes a single attempt at a request without files.
+func (bot *BotAPI) makeRequest(ctx context.Context, endpoint string, params Params) (*APIResponse, error) {
+	if err := bot.waitRateLimit(ctx, endpoint, params); err != nil {
+		return nil, err
+	}
+
 	if bot.Debug {
 		log.Printf("Endpoint: %s, params: %v\n", endpoint, params)
 	}
@@ -100,7 +190,7 @@ func (bot *BotAPI) MakeRequest(endpoint string, params Params) (*APIResponse, er
 
 	values := buildParams(params)
 
-	req, err := http.NewRequest("POST", method, strings.NewReader(values.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", method, strings.NewReader(values.Encode()))
 	if err != nil {
 		return &APIResponse{}, err
 	}
@@ -112,9 +202,33 @@ func (bot *BotAPI) MakeRequest(endpoint string, params Params) (*APIResponse, er
 	}
 	defer resp.Body.Close()
 
+	return bot.handleAPIResponse(endpoint, resp)
+}
+
+// waitRateLimit blocks until bot.RateLimiter allows the request.
+func (bot *BotAPI) waitRateLimit(ctx context.Context, endpoint string, params Params) error {
+	if bot.RateLimiter == nil {
+		return nil
+	}
+
+	return bot.RateLimiter.Wait(ctx, endpoint, params)
+}
+
+// handleAPIResponse decodes the response to a request and converts
+// unsuccessful responses into an Error.
+func (bot *BotAPI) handleAPIResponse(endpoint string, resp *http.Response) (*APIResponse, error) {
 	var apiResp APIResponse
 	bytes, err := bot.decodeAPIResponse(resp.Body, &apiResp)
 	if err != nil {
+		// Proxies in front of the API may answer server errors with a body
+		// that isn't JSON, keep the status so the error can be classified.
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return &apiResp, &Error{
+				Code:    resp.StatusCode,
+				Message: resp.Status,
+			}
+		}
+
 		return &apiResp, err
 	}
 
@@ -165,12 +279,80 @@ func (bot *BotAPI) decodeAPIResponse(responseBody io.Reader, resp *APIResponse)
 
 // UploadFiles makes a request to the API with files.
 func (bot *BotAPI) UploadFiles(endpoint string, params Params, files []RequestFile) (*APIResponse, error) {
+	return bot.UploadFilesContext(context.Background(), endpoint, params, files)
+}
+
+// UploadFilesContext makes a request to the API with files.
+//
+// Cancelling ctx aborts both the HTTP request and the goroutine writing the
+// multipart body. Progress is reported to the function set on ctx with
+// WithUploadProgress. Failed uploads are retried according to bot.RetryPolicy,
+// and resent to migrated chats, only when every file can be read again, see
+// rewindableFiles.
+func (bot *BotAPI) UploadFilesContext(ctx context.Context, endpoint string, params Params, files []RequestFile) (*APIResponse, error) {
+	defer bot.startUploadChatAction(ctx, endpoint, params)()
+
+	rewind, ok := rewindableFiles(files)
+	if !ok {
+		resp, err := bot.uploadFiles(ctx, endpoint, params, files)
+		// The files can't be sent again, but the application should still
+		// learn about the migration.
+		bot.chatMigrated(params, err)
+
+		return resp, err
+	}
+
+	attempt := 0
+
+	upload := func(params Params) (*APIResponse, error) {
+		return bot.withRetry(ctx, endpoint, func() (*APIResponse, error) {
+			if attempt++; attempt > 1 {
+				if err := rewind(); err != nil {
+					return nil, err
+				}
+			}
+
+			return bot.uploadFiles(ctx, endpoint, params, files)
+		})
+	}
+
+	resp, err := upload(params)
+	if migrated, ok := bot.chatMigrated(params, err); ok {
+		return upload(migrated)
+	}
+
+	return resp, err
+}
+
+// uploadFiles makes a single attempt at a request with files.
+func (bot *BotAPI) uploadFiles(ctx context.Context, endpoint string, params Params, files []RequestFile) (*APIResponse, error) {
+	if err := bot.waitRateLimit(ctx, endpoint, params); err != nil {
+		return nil, err
+	}
+
+	progress := newUploadProgress(ctx, files)
+
 	r, w := io.Pipe()
 	m := multipart.NewWriter(w)
 
+	// Closing the read side unblocks the writer goroutine if the request is
+	// cancelled or the client returns without consuming the whole body. It
+	// is waited for so that it no longer reads the files or reports progress
+	// once the attempt is over, as a retry rewinds the same readers.
+	written := make(chan struct{})
+	defer func() {
+		r.Close()
+		<-written
+	}()
+	stop := context.AfterFunc(ctx, func() {
+		r.CloseWithError(ctx.Err())
+	})
+	defer stop()
+
 	// This code modified from the very helpful @HirbodBehnam
 	// https://github.com/go-telegram-bot-api/telegram-bot-api/issues/354#issuecomment-663856473
 	go func() {
+		defer close(written)
 		defer w.Close()
 		defer m.Close()
 
@@ -182,6 +364,11 @@ func (bot *BotAPI) UploadFiles(endpoint string, params Params, files []RequestFi
 		}
 
 		for _, file := range files {
+			if err := ctx.Err(); err != nil {
+				w.CloseWithError(err)
+				return
+			}
+
 			if file.Data.NeedsUpload() {
 				name, reader, err := file.Data.UploadData()
 				if err != nil {
@@ -195,7 +382,10 @@ func (bot *BotAPI) UploadFiles(endpoint string, params Params, files []RequestFi
 					return
 				}
 
-				if _, err := io.Copy(part, reader); err != nil {
+				if _, err := io.Copy(part, progress.reader(reader)); err != nil {
+					if closer, ok := reader.(io.ReadCloser); ok {
+						closer.Close()
+					}
 					w.CloseWithError(err)
 					return
 				}
@@ -223,7 +413,7 @@ func (bot *BotAPI) UploadFiles(endpoint string, params Params, files []RequestFi
 
 	method := fmt.Sprintf(bot.apiEndpoint, bot.Token, endpoint)
 
-	req, err := http.NewRequest("POST", method, r)
+	req, err := http.NewRequestWithContext(ctx, "POST", method, r)
 	if err != nil {
 		return nil, err
 	}
@@ -236,43 +426,25 @@ func (bot *BotAPI) UploadFiles(endpoint string, params Params, files []RequestFi
 	}
 	defer resp.Body.Close()
 
-	var apiResp APIResponse
-	bytes, err := bot.decodeAPIResponse(resp.Body, &apiResp)
-	if err != nil {
-		return &apiResp, err
-	}
-
-	if bot.Debug {
-		log.Printf("Endpoint: %s, response: %s\n", endpoint, string(bytes))
-	}
-
-	if !apiResp.Ok {
-		var parameters ResponseParameters
-
-		if apiResp.Parameters != nil {
-			parameters = *apiResp.Parameters
-		}
-
-		return &apiResp, &Error{
-			Message:            apiResp.Description,
-			ResponseParameters: parameters,
-		}
-	}
-
-	return &apiResp, nil
+	return bot.handleAPIResponse(endpoint, resp)
 }
 
 // GetFileDirectURL returns direct URL to file
 //
 // It requires the FileID.
 func (bot *BotAPI) GetFileDirectURL(fileID string) (string, error) {
-	file, err := bot.GetFile(FileConfig{fileID})
+	return bot.GetFileDirectURLContext(context.Background(), fileID)
+}
+
+// GetFileDirectURLContext is the same as GetFileDirectURL except it accepts a context.
+func (bot *BotAPI) GetFileDirectURLContext(ctx context.Context, fileID string) (string, error) {
+	file, err := bot.GetFileContext(ctx, FileConfig{fileID})
 
 	if err != nil {
 		return "", err
 	}
 
-	return file.Link(bot.Token), nil
+	return bot.fileURL(file.FilePath), nil
 }
 
 // GetMe fetches the currently authenticated bot.
@@ -281,7 +453,12 @@ func (bot *BotAPI) GetFileDirectURL(fileID string) (string, error) {
 // and so you may get this data from BotAPI.Self without the need for
 // another request.
 func (bot *BotAPI) GetMe() (User, error) {
-	resp, err := bot.MakeRequest("getMe", nil)
+	return bot.GetMeContext(context.Background())
+}
+
+// GetMeContext is the same as GetMe except it accepts a context.
+func (bot *BotAPI) GetMeContext(ctx context.Context) (User, error) {
+	resp, err := bot.MakeRequestContext(ctx, "getMe", nil)
 	if err != nil {
 		return User{}, err
 	}
@@ -311,6 +488,11 @@ func hasFilesNeedingUpload(files []RequestFile) bool {
 
 // Request sends a Chattable to Telegram, and returns the APIResponse.
 func (bot *BotAPI) Request(c Chattable) (*APIResponse, error) {
+	return bot.RequestContext(context.Background(), c)
+}
+
+// RequestContext is the same as Request except it accepts a context.
+func (bot *BotAPI) RequestContext(ctx context.Context, c Chattable) (*APIResponse, error) {
 	params, err := c.params()
 	if err != nil {
 		return nil, err
@@ -319,20 +501,38 @@ func (bot *BotAPI) Request(c Chattable) (*APIResponse, error) {
 	if t, ok := c.(Fileable); ok {
 		files := t.files()
 
-		// If we have files that need to be uploaded, we should delegate the
-		// request to UploadFile.
-		if hasFilesNeedingUpload(files) {
-			return bot.UploadFiles(t.method(), params, files)
+		if bot.localServer {
+			if files, err = localFiles(files, params); err != nil {
+				return nil, err
+			}
 		}
 
-		// However, if there are no files to be uploaded, there's likely things
-		// that need to be turned into params instead.
-		for _, file := range files {
-			params[file.Name] = file.Data.SendData()
+		if bot.FileIDCache != nil {
+			return bot.requestWithFileIDCache(ctx, t.method(), params, files)
 		}
+
+		return bot.requestFiles(ctx, t.method(), params, files)
+	}
+
+	return bot.MakeRequestContext(ctx, c.method(), params)
+}
+
+// requestFiles makes a request with files, which are only uploaded if
+// needed.
+func (bot *BotAPI) requestFiles(ctx context.Context, method string, params Params, files []RequestFile) (*APIResponse, error) {
+	// If we have files that need to be uploaded, we should delegate the
+	// request to UploadFile.
+	if hasFilesNeedingUpload(files) {
+		return bot.UploadFilesContext(ctx, method, params, files)
+	}
+
+	// However, if there are no files to be uploaded, there's likely things
+	// that need to be turned into params instead.
+	for _, file := range files {
+		params[file.Name] = file.Data.SendData()
 	}
 
-	return bot.MakeRequest(c.method(), params)
+	return bot.MakeRequestContext(ctx, method, params)
 }
 
 // Send will send a Chattable item to Telegram and provides the
@@ -340,7 +540,12 @@ func (bot *BotAPI) Request(c Chattable) (*APIResponse, error) {
 // NOTE: doesn't check if text is markdown mode, but murkdown does set.
 // NOTE: Service messages about forum topic creation can't be deleted with the deleteMessage method.
 func (bot *BotAPI) Send(c Chattable) (Message, error) {
-	resp, err := bot.Request(c)
+	return bot.SendContext(context.Background(), c)
+}
+
+// SendContext is the same as Send except it accepts a context.
+func (bot *BotAPI) SendContext(ctx context.Context, c Chattable) (Message, error) {
+	resp, err := bot.RequestContext(ctx, c)
 	if err != nil {
 		return Message{}, err
 	}
@@ -353,7 +558,12 @@ func (bot *BotAPI) Send(c Chattable) (Message, error) {
 
 // SendMediaGroup sends a media group and returns the resulting messages.
 func (bot *BotAPI) SendMediaGroup(config MediaGroupConfig) ([]Message, error) {
-	resp, err := bot.Request(config)
+	return bot.SendMediaGroupContext(context.Background(), config)
+}
+
+// SendMediaGroupContext is the same as SendMediaGroup except it accepts a context.
+func (bot *BotAPI) SendMediaGroupContext(ctx context.Context, config MediaGroupConfig) ([]Message, error) {
+	resp, err := bot.RequestContext(ctx, config)
 	if err != nil {
 		return nil, err
 	}
@@ -369,7 +579,12 @@ func (bot *BotAPI) SendMediaGroup(config MediaGroupConfig) ([]Message, error) {
 // It requires UserID.
 // Offset and Limit are optional.
 func (bot *BotAPI) GetUserProfilePhotos(config UserProfilePhotosConfig) (UserProfilePhotos, error) {
-	resp, err := bot.Request(config)
+	return bot.GetUserProfilePhotosContext(context.Background(), config)
+}
+
+// GetUserProfilePhotosContext is the same as GetUserProfilePhotos except it accepts a context.
+func (bot *BotAPI) GetUserProfilePhotosContext(ctx context.Context, config UserProfilePhotosConfig) (UserProfilePhotos, error) {
+	resp, err := bot.RequestContext(ctx, config)
 	if err != nil {
 		return UserProfilePhotos{}, err
 	}
@@ -384,7 +599,12 @@ func (bot *BotAPI) GetUserProfilePhotos(config UserProfilePhotosConfig) (UserPro
 //
 // Requires FileID.
 func (bot *BotAPI) GetFile(config FileConfig) (File, error) {
-	resp, err := bot.Request(config)
+	return bot.GetFileContext(context.Background(), config)
+}
+
+// GetFileContext is the same as GetFile except it accepts a context.
+func (bot *BotAPI) GetFileContext(ctx context.Context, config FileConfig) (File, error) {
+	resp, err := bot.RequestContext(ctx, config)
 	if err != nil {
 		return File{}, err
 	}
@@ -403,7 +623,12 @@ func (bot *BotAPI) GetFile(config FileConfig) (File, error) {
 // Set Timeout to a large number to reduce requests, so you can get updates
 // instantly instead of having to wait between requests.
 func (bot *BotAPI) GetUpdates(config UpdateConfig) ([]Update, error) {
-	resp, err := bot.Request(config)
+	return bot.GetUpdatesContext(context.Background(), config)
+}
+
+// GetUpdatesContext is the same as GetUpdates except it accepts a context.
+func (bot *BotAPI) GetUpdatesContext(ctx context.Context, config UpdateConfig) ([]Update, error) {
+	resp, err := bot.RequestContext(ctx, config)
 	if err != nil {
 		return []Update{}, err
 	}
@@ -417,7 +642,12 @@ func (bot *BotAPI) GetUpdates(config UpdateConfig) ([]Update, error) {
 // GetWebhookInfo allows you to fetch information about a webhook and if
 // one currently is set, along with pending update count and error messages.
 func (bot *BotAPI) GetWebhookInfo() (WebhookInfo, error) {
-	resp, err := bot.MakeRequest("getWebhookInfo", nil)
+	return bot.GetWebhookInfoContext(context.Background())
+}
+
+// GetWebhookInfoContext is the same as GetWebhookInfo except it accepts a context.
+func (bot *BotAPI) GetWebhookInfoContext(ctx context.Context) (WebhookInfo, error) {
+	resp, err := bot.MakeRequestContext(ctx, "getWebhookInfo", nil)
 	if err != nil {
 		return WebhookInfo{}, err
 	}
@@ -430,31 +660,117 @@ func (bot *BotAPI) GetWebhookInfo() (WebhookInfo, error) {
 
 // GetUpdatesChan starts and returns a channel for getting updates.
 func (bot *BotAPI) GetUpdatesChan(config UpdateConfig) UpdatesChannel {
+	return bot.GetUpdatesChanContext(context.Background(), config)
+}
+
+// GetUpdatesChanContext starts and returns a channel for getting updates.
+//
+// Polling stops when ctx is done or StopReceivingUpdates is called. The
+// in-flight getUpdates request is aborted and the returned channel is closed
+// once the polling goroutine exits. Updates that were fetched but not yet
+// delivered to the channel are not committed, so starting again from
+// UpdatesOffset neither loses nor replays updates.
+//
+// If OffsetStore is set, polling starts from the stored offset instead of
+// config.Offset when one is stored, and only updates acknowledged with
+// AckUpdate are committed.
+func (bot *BotAPI) GetUpdatesChanContext(ctx context.Context, config UpdateConfig) UpdatesChannel {
 	ch := make(chan Update, bot.Buffer)
 
+	p := bot.startPolling()
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(p.ctx, cancel)
+
+	var acks *updateAcks
+	if bot.OffsetStore != nil {
+		offset, err := bot.OffsetStore.LoadOffset(ctx)
+		if err != nil {
+			log.Printf("Failed to load updates offset: %v", err)
+		} else if offset != 0 {
+			config.Offset = offset
+		}
+
+		acks = newUpdateAcks(bot.OffsetStore, config.Offset)
+	}
+	bot.acks.Store(acks)
+
+	bot.updatesOffset.Store(int64(config.Offset))
+
 	go func() {
+		defer p.wg.Done()
+		defer close(ch)
+		defer stop()
+		defer cancel()
+
+		failures := 0
+
 		for {
-			select {
-			case <-bot.shutdownChannel:
-				close(ch)
-				return
-			default:
+			if acks != nil {
+				config.Offset = acks.offset()
 			}
 
-			updates, err := bot.GetUpdates(config)
+			updates, err := bot.GetUpdatesContext(ctx, config)
 			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+
+				failures++
+				delay := bot.pollRetryDelay(err, failures)
+
 				log.Println(err)
-				log.Println("Failed to get updates, retrying in 3 seconds...")
-				time.Sleep(time.Second * 3)
+				log.Printf("Failed to get updates, retrying in %s...", delay)
+
+				if !sleepContext(ctx, delay) {
+					return
+				}
 
 				continue
 			}
 
+			failures = 0
+
+			sent := 0
+
 			for _, update := range updates {
-				if update.UpdateID >= config.Offset {
+				if acks != nil {
+					if !acks.isNew(update.UpdateID) {
+						continue
+					}
+					acks.deliver(update.UpdateID)
+				} else if update.UpdateID < config.Offset {
+					continue
+				}
+
+				if bot.isDuplicate(ctx, update) {
+					if acks != nil {
+						if err := acks.ack(ctx, update.UpdateID); err != nil {
+							log.Printf("Failed to acknowledge update %d: %v", update.UpdateID, err)
+						}
+					} else {
+						config.Offset = update.UpdateID + 1
+					}
+					continue
+				}
+
+				select {
+				case ch <- update:
+				case <-ctx.Done():
+					bot.updateDropped(update)
+					return
+				}
+
+				sent++
+				if acks == nil {
 					config.Offset = update.UpdateID + 1
-					ch <- update
 				}
+				bot.updatesOffset.Store(int64(update.UpdateID + 1))
+			}
+
+			// Telegram keeps returning updates that weren't acknowledged, so
+			// wait for an acknowledgement before asking again.
+			if acks != nil && sent == 0 && len(updates) > 0 && !acks.wait(ctx) {
+				return
 			}
 		}
 	}()
@@ -462,15 +778,63 @@ func (bot *BotAPI) GetUpdatesChan(config UpdateConfig) UpdatesChannel {
 	return ch
 }
 
-// StopReceivingUpdates stops the go routine which receives updates
+// startPolling registers a new polling goroutine with the current poller,
+// creating one if updates are not being received yet.
+func (bot *BotAPI) startPolling() *updatesPoller {
+	bot.pollMu.Lock()
+	defer bot.pollMu.Unlock()
+
+	if bot.poller == nil {
+		p := &updatesPoller{}
+		p.ctx, p.cancel = context.WithCancel(context.Background())
+		bot.poller = p
+	}
+
+	bot.poller.wg.Add(1)
+
+	return bot.poller
+}
+
+// UpdatesOffset returns the offset following the last update delivered by
+// GetUpdatesChan. Pass it to NewUpdate to resume receiving updates.
+//
+// If OffsetStore is set, it returns the committed offset instead, which
+// doesn't move past updates that weren't acknowledged.
+func (bot *BotAPI) UpdatesOffset() int {
+	if acks := bot.acks.Load(); acks != nil {
+		return acks.offset()
+	}
+
+	return int(bot.updatesOffset.Load())
+}
+
+// StopReceivingUpdates stops the go routines which receive updates and waits
+// for their channels to be closed.
+//
+// It is safe to call more than once, and GetUpdatesChan may be called again
+// afterwards to restart receiving updates.
 func (bot *BotAPI) StopReceivingUpdates() {
 	if bot.Debug {
 		log.Println("Stopping the update receiver routine...")
 	}
-	close(bot.shutdownChannel)
+
+	bot.pollMu.Lock()
+	p := bot.poller
+	bot.poller = nil
+	bot.pollMu.Unlock()
+
+	if p == nil {
+		return
+	}
+
+	p.cancel()
+	p.wg.Wait()
 }
 
 // ListenForWebhook registers a http handler for a webhook.
+//
+// It uses http.DefaultServeMux and doesn't check the secret token, see
+// WebhookServer for a handler that does.
 func (bot *BotAPI) ListenForWebhook(pattern string) UpdatesChannel {
 	ch := make(chan Update, bot.Buffer)
 
@@ -484,6 +848,10 @@ func (bot *BotAPI) ListenForWebhook(pattern string) UpdatesChannel {
 			return
 		}
 
+		if bot.isDuplicate(r.Context(), *update) {
+			return
+		}
+
 		ch <- *update
 	})
 
@@ -506,6 +874,10 @@ func (bot *BotAPI) ListenForWebhookRespReqFormat(w http.ResponseWriter, r *http.
 			return
 		}
 
+		if bot.isDuplicate(r.Context(), *update) {
+			return
+		}
+
 		ch <- *update
 	}(w, r)
 
@@ -530,7 +902,8 @@ func (bot *BotAPI) HandleUpdate(r *http.Request) (*Update, error) {
 
 // WriteToHTTPResponse writes the request to the HTTP ResponseWriter.
 //
-// It doesn't support uploading files.
+// It doesn't support uploading files. Reply falls back to a regular request
+// for those.
 //
 // See https://core.telegram.org/bots/api#making-requests-when-getting-updates
 // for details.
@@ -541,9 +914,16 @@ func WriteToHTTPResponse(w http.ResponseWriter, c Chattable) error {
 	}
 
 	if t, ok := c.(Fileable); ok {
-		if hasFilesNeedingUpload(t.files()) {
+		files := t.files()
+		if hasFilesNeedingUpload(files) {
 			return errors.New("unable to use http response to upload files")
 		}
+
+		// Files that don't need uploading are sent as params, as in
+		// requestFiles.
+		for _, file := range files {
+			params[file.Name] = file.Data.SendData()
+		}
 	}
 
 	values := buildParams(params)
@@ -556,7 +936,12 @@ func WriteToHTTPResponse(w http.ResponseWriter, c Chattable) error {
 
 // GetChat gets information about a chat.
 func (bot *BotAPI) GetChat(config ChatInfoConfig) (Chat, error) {
-	resp, err := bot.Request(config)
+	return bot.GetChatContext(context.Background(), config)
+}
+
+// GetChatContext is the same as GetChat except it accepts a context.
+func (bot *BotAPI) GetChatContext(ctx context.Context, config ChatInfoConfig) (Chat, error) {
+	resp, err := bot.RequestContext(ctx, config)
 	if err != nil {
 		return Chat{}, err
 	}
@@ -571,6 +956,11 @@ func (bot *BotAPI) GetChat(config ChatInfoConfig) (Chat, error) {
 // username or groupname of supergroup must be passed by @...
 // Example: @tggobotapitest
 func (bot *BotAPI) GetUserIDbyUsername(username string) (int64, error) {
+	return bot.GetUserIDbyUsernameContext(context.Background(), username)
+}
+
+// GetUserIDbyUsernameContext is the same as GetUserIDbyUsername except it accepts a context.
+func (bot *BotAPI) GetUserIDbyUsernameContext(ctx context.Context, username string) (int64, error) {
 	var chat Chat
 
 	config := ChatInfoConfig{
@@ -579,7 +969,7 @@ func (bot *BotAPI) GetUserIDbyUsername(username string) (int64, error) {
 		},
 	}
 
-	resp, err := bot.Request(config)
+	resp, err := bot.RequestContext(ctx, config)
 	if err != nil {
 		return -1, err
 	}
@@ -594,7 +984,12 @@ func (bot *BotAPI) GetUserIDbyUsername(username string) (int64, error) {
 // If none have been appointed, only the creator will be returned.
 // Bots are not shown, even if they are an administrator.
 func (bot *BotAPI) GetChatAdministrators(config ChatAdministratorsConfig) ([]ChatMember, error) {
-	resp, err := bot.Request(config)
+	return bot.GetChatAdministratorsContext(context.Background(), config)
+}
+
+// GetChatAdministratorsContext is the same as GetChatAdministrators except it accepts a context.
+func (bot *BotAPI) GetChatAdministratorsContext(ctx context.Context, config ChatAdministratorsConfig) ([]ChatMember, error) {
+	resp, err := bot.RequestContext(ctx, config)
 	if err != nil {
 		return []ChatMember{}, err
 	}
@@ -607,7 +1002,12 @@ func (bot *BotAPI) GetChatAdministrators(config ChatAdministratorsConfig) ([]Cha
 
 // GetChatMembersCount gets the number of users in a chat.
 func (bot *BotAPI) GetChatMembersCount(config ChatMemberCountConfig) (int, error) {
-	resp, err := bot.Request(config)
+	return bot.GetChatMembersCountContext(context.Background(), config)
+}
+
+// GetChatMembersCountContext is the same as GetChatMembersCount except it accepts a context.
+func (bot *BotAPI) GetChatMembersCountContext(ctx context.Context, config ChatMemberCountConfig) (int, error) {
+	resp, err := bot.RequestContext(ctx, config)
 	if err != nil {
 		return -1, err
 	}
@@ -621,7 +1021,12 @@ func (bot *BotAPI) GetChatMembersCount(config ChatMemberCountConfig) (int, error
 // GetChatMember gets a specific chat member.
 // Note that the method is only guaranteed to work if the bot is an administrator in the chat.
 func (bot *BotAPI) GetChatMember(config GetChatMemberConfig) (ChatMember, error) {
-	resp, err := bot.Request(config)
+	return bot.GetChatMemberContext(context.Background(), config)
+}
+
+// GetChatMemberContext is the same as GetChatMember except it accepts a context.
+func (bot *BotAPI) GetChatMemberContext(ctx context.Context, config GetChatMemberConfig) (ChatMember, error) {
+	resp, err := bot.RequestContext(ctx, config)
 	if err != nil {
 		return ChatMember{}, err
 	}
@@ -634,7 +1039,12 @@ func (bot *BotAPI) GetChatMember(config GetChatMemberConfig) (ChatMember, error)
 
 // GetGameHighScores allows you to get the high scores for a game.
 func (bot *BotAPI) GetGameHighScores(config GetGameHighScoresConfig) ([]GameHighScore, error) {
-	resp, err := bot.Request(config)
+	return bot.GetGameHighScoresContext(context.Background(), config)
+}
+
+// GetGameHighScoresContext is the same as GetGameHighScores except it accepts a context.
+func (bot *BotAPI) GetGameHighScoresContext(ctx context.Context, config GetGameHighScoresConfig) ([]GameHighScore, error) {
+	resp, err := bot.RequestContext(ctx, config)
 	if err != nil {
 		return []GameHighScore{}, err
 	}
@@ -647,7 +1057,12 @@ func (bot *BotAPI) GetGameHighScores(config GetGameHighScoresConfig) ([]GameHigh
 
 // GetInviteLink get InviteLink for a chat
 func (bot *BotAPI) GetInviteLink(config ChatInviteLinkConfig) (string, error) {
-	resp, err := bot.Request(config)
+	return bot.GetInviteLinkContext(context.Background(), config)
+}
+
+// GetInviteLinkContext is the same as GetInviteLink except it accepts a context.
+func (bot *BotAPI) GetInviteLinkContext(ctx context.Context, config ChatInviteLinkConfig) (string, error) {
+	resp, err := bot.RequestContext(ctx, config)
 	if err != nil {
 		return "", err
 	}
@@ -660,7 +1075,12 @@ func (bot *BotAPI) GetInviteLink(config ChatInviteLinkConfig) (string, error) {
 
 // GetStickerSet returns a StickerSet.
 func (bot *BotAPI) GetStickerSet(config GetStickerSetConfig) (StickerSet, error) {
-	resp, err := bot.Request(config)
+	return bot.GetStickerSetContext(context.Background(), config)
+}
+
+// GetStickerSetContext is the same as GetStickerSet except it accepts a context.
+func (bot *BotAPI) GetStickerSetContext(ctx context.Context, config GetStickerSetConfig) (StickerSet, error) {
+	resp, err := bot.RequestContext(ctx, config)
 	if err != nil {
 		return StickerSet{}, err
 	}
@@ -673,7 +1093,12 @@ func (bot *BotAPI) GetStickerSet(config GetStickerSetConfig) (StickerSet, error)
 
 // StopPoll stops a poll and returns the result.
 func (bot *BotAPI) StopPoll(config StopPollConfig) (Poll, error) {
-	resp, err := bot.Request(config)
+	return bot.StopPollContext(context.Background(), config)
+}
+
+// StopPollContext is the same as StopPoll except it accepts a context.
+func (bot *BotAPI) StopPollContext(ctx context.Context, config StopPollConfig) (Poll, error) {
+	resp, err := bot.RequestContext(ctx, config)
 	if err != nil {
 		return Poll{}, err
 	}
@@ -689,9 +1114,19 @@ func (bot *BotAPI) GetMyCommands() ([]BotCommand, error) {
 	return bot.GetMyCommandsWithConfig(GetMyCommandsConfig{})
 }
 
+// GetMyCommandsContext is the same as GetMyCommands except it accepts a context.
+func (bot *BotAPI) GetMyCommandsContext(ctx context.Context) ([]BotCommand, error) {
+	return bot.GetMyCommandsWithConfigContext(ctx, GetMyCommandsConfig{})
+}
+
 // GetMyCommandsWithConfig gets the currently registered commands with a config.
 func (bot *BotAPI) GetMyCommandsWithConfig(config GetMyCommandsConfig) ([]BotCommand, error) {
-	resp, err := bot.Request(config)
+	return bot.GetMyCommandsWithConfigContext(context.Background(), config)
+}
+
+// GetMyCommandsWithConfigContext is the same as GetMyCommandsWithConfig except it accepts a context.
+func (bot *BotAPI) GetMyCommandsWithConfigContext(ctx context.Context, config GetMyCommandsConfig) ([]BotCommand, error) {
+	resp, err := bot.RequestContext(ctx, config)
 	if err != nil {
 		return nil, err
 	}
@@ -702,11 +1137,70 @@ func (bot *BotAPI) GetMyCommandsWithConfig(config GetMyCommandsConfig) ([]BotCom
 	return commands, err
 }
 
+// GetMyName gets the bot's name for a language.
+func (bot *BotAPI) GetMyName(config GetMyNameConfig) (BotName, error) {
+	return bot.GetMyNameContext(context.Background(), config)
+}
+
+// GetMyNameContext is the same as GetMyName except it accepts a context.
+func (bot *BotAPI) GetMyNameContext(ctx context.Context, config GetMyNameConfig) (BotName, error) {
+	resp, err := bot.RequestContext(ctx, config)
+	if err != nil {
+		return BotName{}, err
+	}
+
+	var name BotName
+	err = json.Unmarshal(resp.Result, &name)
+
+	return name, err
+}
+
+// GetMyDescription gets the bot's description for a language.
+func (bot *BotAPI) GetMyDescription(config GetMyDescriptionConfig) (BotDescription, error) {
+	return bot.GetMyDescriptionContext(context.Background(), config)
+}
+
+// GetMyDescriptionContext is the same as GetMyDescription except it accepts a context.
+func (bot *BotAPI) GetMyDescriptionContext(ctx context.Context, config GetMyDescriptionConfig) (BotDescription, error) {
+	resp, err := bot.RequestContext(ctx, config)
+	if err != nil {
+		return BotDescription{}, err
+	}
+
+	var description BotDescription
+	err = json.Unmarshal(resp.Result, &description)
+
+	return description, err
+}
+
+// GetMyShortDescription gets the bot's short description for a language.
+func (bot *BotAPI) GetMyShortDescription(config GetMyShortDescriptionConfig) (BotShortDescription, error) {
+	return bot.GetMyShortDescriptionContext(context.Background(), config)
+}
+
+// GetMyShortDescriptionContext is the same as GetMyShortDescription except it accepts a context.
+func (bot *BotAPI) GetMyShortDescriptionContext(ctx context.Context, config GetMyShortDescriptionConfig) (BotShortDescription, error) {
+	resp, err := bot.RequestContext(ctx, config)
+	if err != nil {
+		return BotShortDescription{}, err
+	}
+
+	var description BotShortDescription
+	err = json.Unmarshal(resp.Result, &description)
+
+	return description, err
+}
+
 // CopyMessage copy messages of any kind. The method is analogous to the method
 // forwardMessage, but the copied message doesn't have a link to the original
 // message. Returns the MessageID of the sent message on success.
 func (bot *BotAPI) CopyMessage(config CopyMessageConfig) (MessageID, error) {
-	resp, err := bot.Request(config)
+	return bot.CopyMessageContext(context.Background(), config)
+}
+
+// CopyMessageContext is the same as CopyMessage except it accepts a context.
+func (bot *BotAPI) CopyMessageContext(ctx context.Context, config CopyMessageConfig) (MessageID, error) {
+	resp, err := bot.RequestContext(ctx, config)
 	if err != nil {
 		return MessageID{}, err
 	}
@@ -720,9 +1214,14 @@ func (bot *BotAPI) CopyMessage(config CopyMessageConfig) (MessageID, error) {
 // AnswerWebAppQuery sets the result of an interaction with a Web App and send a
 // corresponding message on behalf of the user to the chat from which the query originated.
 func (bot *BotAPI) AnswerWebAppQuery(config AnswerWebAppQueryConfig) (SentWebAppMessage, error) {
+	return bot.AnswerWebAppQueryContext(context.Background(), config)
+}
+
+// AnswerWebAppQueryContext is the same as AnswerWebAppQuery except it accepts a context.
+func (bot *BotAPI) AnswerWebAppQueryContext(ctx context.Context, config AnswerWebAppQueryConfig) (SentWebAppMessage, error) {
 	var sentWebAppMessage SentWebAppMessage
 
-	resp, err := bot.Request(config)
+	resp, err := bot.RequestContext(ctx, config)
 	if err != nil {
 		return sentWebAppMessage, err
 	}
@@ -733,9 +1232,14 @@ func (bot *BotAPI) AnswerWebAppQuery(config AnswerWebAppQueryConfig) (SentWebApp
 
 // GetMyDefaultAdministratorRights gets the current default administrator rights of the bot.
 func (bot *BotAPI) GetMyDefaultAdministratorRights(config GetMyDefaultAdministratorRightsConfig) (ChatAdministratorRights, error) {
+	return bot.GetMyDefaultAdministratorRightsContext(context.Background(), config)
+}
+
+// GetMyDefaultAdministratorRightsContext is the same as GetMyDefaultAdministratorRights except it accepts a context.
+func (bot *BotAPI) GetMyDefaultAdministratorRightsContext(ctx context.Context, config GetMyDefaultAdministratorRightsConfig) (ChatAdministratorRights, error) {
 	var rights ChatAdministratorRights
 
-	resp, err := bot.Request(config)
+	resp, err := bot.RequestContext(ctx, config)
 	if err != nil {
 		return rights, err
 	}
@@ -746,7 +1250,12 @@ func (bot *BotAPI) GetMyDefaultAdministratorRights(config GetMyDefaultAdministra
 
 // CreateInvoiceLink отправляет createInvoiceLink и возвращает ссылку на оплату.
 func (bot *BotAPI) CreateInvoiceLink(config CreateInvoiceLinkConfig) (string, error) {
-	resp, err := bot.Request(config)
+	return bot.CreateInvoiceLinkContext(context.Background(), config)
+}
+
+// CreateInvoiceLinkContext is the same as CreateInvoiceLink except it accepts a context.
+func (bot *BotAPI) CreateInvoiceLinkContext(ctx context.Context, config CreateInvoiceLinkConfig) (string, error) {
+	resp, err := bot.RequestContext(ctx, config)
 	if err != nil {
 		return "", err
 	}
@@ -762,9 +1271,14 @@ func (bot *BotAPI) CreateInvoiceLink(config CreateInvoiceLinkConfig) (string, er
 // Be cearful, you can't create more than 20 topics in a forum supergroup. This methods can create the same topics name.
 // If you want set custom sticker to Topic use before bot.GetForumTopicIconStickers(GetForumTopicIconStickersConfig{}).
 func (bot *BotAPI) CreateForumTopic(config CreateForumTopicConfig) (ForumTopic, error) {
+	return bot.CreateForumTopicContext(context.Background(), config)
+}
+
+// CreateForumTopicContext is the same as CreateForumTopic except it accepts a context.
+func (bot *BotAPI) CreateForumTopicContext(ctx context.Context, config CreateForumTopicConfig) (ForumTopic, error) {
 	var topic ForumTopic
 
-	resp, err := bot.Request(config)
+	resp, err := bot.RequestContext(ctx, config)
 	if err != nil {
 		return topic, err
 	}
@@ -776,7 +1290,12 @@ func (bot *BotAPI) CreateForumTopic(config CreateForumTopicConfig) (ForumTopic,
 // EditForumTopic calls Telegram Bot API EditForumTopic method.
 // If you try to change topic to the same, you'll get an error.
 func (bot *BotAPI) EditForumTopic(config EditForumTopicConfig) (*APIResponse, error) {
-	resp, err := bot.Request(config)
+	return bot.EditForumTopicContext(context.Background(), config)
+}
+
+// EditForumTopicContext is the same as EditForumTopic except it accepts a context.
+func (bot *BotAPI) EditForumTopicContext(ctx context.Context, config EditForumTopicConfig) (*APIResponse, error) {
+	resp, err := bot.RequestContext(ctx, config)
 	if err != nil {
 		return resp, err
 	}
@@ -786,7 +1305,12 @@ func (bot *BotAPI) EditForumTopic(config EditForumTopicConfig) (*APIResponse, er
 
 // CloseForumTopic calls Telegram Bot API CloseForumTopic method.
 func (bot *BotAPI) CloseForumTopic(config CloseForumTopicConfig) (*APIResponse, error) {
-	resp, err := bot.Request(config)
+	return bot.CloseForumTopicContext(context.Background(), config)
+}
+
+// CloseForumTopicContext is the same as CloseForumTopic except it accepts a context.
+func (bot *BotAPI) CloseForumTopicContext(ctx context.Context, config CloseForumTopicConfig) (*APIResponse, error) {
+	resp, err := bot.RequestContext(ctx, config)
 	if err != nil {
 		return resp, err
 	}
@@ -796,7 +1320,12 @@ func (bot *BotAPI) CloseForumTopic(config CloseForumTopicConfig) (*APIResponse,
 
 // ReopenForumTopic calls Telegram Bot API ReopenForumTopic method.
 func (bot *BotAPI) ReopenForumTopic(config ReopenForumTopicConfig) (*APIResponse, error) {
-	resp, err := bot.Request(config)
+	return bot.ReopenForumTopicContext(context.Background(), config)
+}
+
+// ReopenForumTopicContext is the same as ReopenForumTopic except it accepts a context.
+func (bot *BotAPI) ReopenForumTopicContext(ctx context.Context, config ReopenForumTopicConfig) (*APIResponse, error) {
+	resp, err := bot.RequestContext(ctx, config)
 	if err != nil {
 		return resp, err
 	}
@@ -806,7 +1335,12 @@ func (bot *BotAPI) ReopenForumTopic(config ReopenForumTopicConfig) (*APIResponse
 
 // DeleteForumTopic calls Telegram Bot API DeleteForumTopic method.
 func (bot *BotAPI) DeleteForumTopic(config DeleteForumTopicConfig) (*APIResponse, error) {
-	resp, err := bot.Request(config)
+	return bot.DeleteForumTopicContext(context.Background(), config)
+}
+
+// DeleteForumTopicContext is the same as DeleteForumTopic except it accepts a context.
+func (bot *BotAPI) DeleteForumTopicContext(ctx context.Context, config DeleteForumTopicConfig) (*APIResponse, error) {
+	resp, err := bot.RequestContext(ctx, config)
 	if err != nil {
 		return resp, err
 	}
@@ -816,7 +1350,12 @@ func (bot *BotAPI) DeleteForumTopic(config DeleteForumTopicConfig) (*APIResponse
 
 // UnpinAllForumTopicMessages calls Telegram Bot API UnpinAllForumTopicMessages method.
 func (bot *BotAPI) UnpinAllForumTopicMessages(config UnpinAllForumTopicMessagesConfig) (*APIResponse, error) {
-	resp, err := bot.Request(config)
+	return bot.UnpinAllForumTopicMessagesContext(context.Background(), config)
+}
+
+// UnpinAllForumTopicMessagesContext is the same as UnpinAllForumTopicMessages except it accepts a context.
+func (bot *BotAPI) UnpinAllForumTopicMessagesContext(ctx context.Context, config UnpinAllForumTopicMessagesConfig) (*APIResponse, error) {
+	resp, err := bot.RequestContext(ctx, config)
 	if err != nil {
 		return resp, err
 	}
@@ -826,9 +1365,14 @@ func (bot *BotAPI) UnpinAllForumTopicMessages(config UnpinAllForumTopicMessagesC
 
 // GetForumTopicIconStickers returns stickers that can be used as forum topic icons.
 func (bot *BotAPI) GetForumTopicIconStickers() ([]Sticker, error) {
+	return bot.GetForumTopicIconStickersContext(context.Background())
+}
+
+// GetForumTopicIconStickersContext is the same as GetForumTopicIconStickers except it accepts a context.
+func (bot *BotAPI) GetForumTopicIconStickersContext(ctx context.Context) ([]Sticker, error) {
 	var stickers []Sticker
 
-	resp, err := bot.Request(GetCustomEmojiStickersConfig{})
+	resp, err := bot.RequestContext(ctx, GetCustomEmojiStickersConfig{})
 	if err != nil {
 		return stickers, err
 	}
@@ -840,6 +1384,11 @@ func (bot *BotAPI) GetForumTopicIconStickers() ([]Sticker, error) {
 
 // SendAction — universal methods sendChatAction.
 func (bot *BotAPI) SendAction(to any, action ChatAction) (*APIResponse, error) {
+	return bot.SendActionContext(context.Background(), to, action)
+}
+
+// SendActionContext is the same as SendAction except it accepts a context.
+func (bot *BotAPI) SendActionContext(ctx context.Context, to any, action ChatAction) (*APIResponse, error) {
 	toID := getChatID(to)
 	var config ChatActionConfig
 
@@ -849,7 +1398,7 @@ func (bot *BotAPI) SendAction(to any, action ChatAction) (*APIResponse, error) {
 		config = NewChatActionConfig(toID, action)
 	}
 
-	resp, err := bot.Request(config)
+	resp, err := bot.RequestContext(ctx, config)
 	if err != nil {
 		return resp, err
 	}
@@ -914,8 +1463,13 @@ func (bot *BotAPI) UploadVideoNote(to any) (*APIResponse, error) {
 
 // GetCustomEmojiStickers returns an array of stickers belonging to a set of custom emoji identifiers.
 func (b *BotAPI) GetCustomEmojiStickers(ids []string) ([]Sticker, error) {
+	return b.GetCustomEmojiStickersContext(context.Background(), ids)
+}
+
+// GetCustomEmojiStickersContext is the same as GetCustomEmojiStickers except it accepts a context.
+func (b *BotAPI) GetCustomEmojiStickersContext(ctx context.Context, ids []string) ([]Sticker, error) {
 	cfg := GetCustomEmojiStickersConfig{CustomEmojiIDs: ids}
-	resp, err := b.Request(cfg)
+	resp, err := b.RequestContext(ctx, cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -928,7 +1482,12 @@ func (b *BotAPI) GetCustomEmojiStickers(ids []string) ([]Sticker, error) {
 
 // EditGeneralForumTopic calls Telegram Bot API editGeneralForumTopic method.
 func (bot *BotAPI) EditGeneralForumTopic(config EditGeneralForumTopicConfig) (APIResponse, error) {
-	resp, err := bot.Request(config)
+	return bot.EditGeneralForumTopicContext(context.Background(), config)
+}
+
+// EditGeneralForumTopicContext is the same as EditGeneralForumTopic except it accepts a context.
+func (bot *BotAPI) EditGeneralForumTopicContext(ctx context.Context, config EditGeneralForumTopicConfig) (APIResponse, error) {
+	resp, err := bot.RequestContext(ctx, config)
 	if err != nil {
 		return APIResponse{}, err
 	}
@@ -937,7 +1496,12 @@ func (bot *BotAPI) EditGeneralForumTopic(config EditGeneralForumTopicConfig) (AP
 
 // CloseGeneralForumTopic calls Telegram Bot API closeGeneralForumTopic method.
 func (bot *BotAPI) CloseGeneralForumTopic(config CloseGeneralForumTopicConfig) (APIResponse, error) {
-	resp, err := bot.Request(config)
+	return bot.CloseGeneralForumTopicContext(context.Background(), config)
+}
+
+// CloseGeneralForumTopicContext is the same as CloseGeneralForumTopic except it accepts a context.
+func (bot *BotAPI) CloseGeneralForumTopicContext(ctx context.Context, config CloseGeneralForumTopicConfig) (APIResponse, error) {
+	resp, err := bot.RequestContext(ctx, config)
 	if err != nil {
 		return APIResponse{}, err
 	}
@@ -946,7 +1510,12 @@ func (bot *BotAPI) CloseGeneralForumTopic(config CloseGeneralForumTopicConfig) (
 
 // ReopenGeneralForumTopic calls Telegram Bot API reopenGeneralForumTopic method.
 func (bot *BotAPI) ReopenGeneralForumTopic(config ReopenGeneralForumTopicConfig) (APIResponse, error) {
-	resp, err := bot.Request(config)
+	return bot.ReopenGeneralForumTopicContext(context.Background(), config)
+}
+
+// ReopenGeneralForumTopicContext is the same as ReopenGeneralForumTopic except it accepts a context.
+func (bot *BotAPI) ReopenGeneralForumTopicContext(ctx context.Context, config ReopenGeneralForumTopicConfig) (APIResponse, error) {
+	resp, err := bot.RequestContext(ctx, config)
 	if err != nil {
 		return APIResponse{}, err
 	}
@@ -955,7 +1524,12 @@ func (bot *BotAPI) ReopenGeneralForumTopic(config ReopenGeneralForumTopicConfig)
 
 // HideGeneralForumTopic calls Telegram Bot API hideGeneralForumTopic method.
 func (bot *BotAPI) HideGeneralForumTopic(config HideGeneralForumTopicConfig) (APIResponse, error) {
-	resp, err := bot.Request(config)
+	return bot.HideGeneralForumTopicContext(context.Background(), config)
+}
+
+// HideGeneralForumTopicContext is the same as HideGeneralForumTopic except it accepts a context.
+func (bot *BotAPI) HideGeneralForumTopicContext(ctx context.Context, config HideGeneralForumTopicConfig) (APIResponse, error) {
+	resp, err := bot.RequestContext(ctx, config)
 	if err != nil {
 		return APIResponse{}, err
 	}
@@ -964,7 +1538,12 @@ func (bot *BotAPI) HideGeneralForumTopic(config HideGeneralForumTopicConfig) (AP
 
 // UnhideGeneralForumTopic calls Telegram Bot API unhideGeneralForumTopic method.
 func (bot *BotAPI) UnhideGeneralForumTopic(config UnhideGeneralForumTopicConfig) (APIResponse, error) {
-	resp, err := bot.Request(config)
+	return bot.UnhideGeneralForumTopicContext(context.Background(), config)
+}
+
+// UnhideGeneralForumTopicContext is the same as UnhideGeneralForumTopic except it accepts a context.
+func (bot *BotAPI) UnhideGeneralForumTopicContext(ctx context.Context, config UnhideGeneralForumTopicConfig) (APIResponse, error) {
+	resp, err := bot.RequestContext(ctx, config)
 	if err != nil {
 		return APIResponse{}, err
 	}
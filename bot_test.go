@@ -1,11 +1,17 @@
 package tgbotapi
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"reflect"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -670,7 +676,7 @@ func TestSetWebhookWithoutCert(t *testing.T) {
 func TestSendWithMediaGroupPhotoVideo(t *testing.T) {
 	bot := getBot(t)
 
-	cfg := NewMediaGroup(ChatID, []interface{}{
+	cfg := NewMediaGroup(ChatID, []InputMedia{
 		NewInputMediaPhoto(FileURL("https://github.com/go-telegram-bot-api/telegram-bot-api/raw/0a3a1c8716c4cd8d26a262af9f12dcbab7f3f28c/tests/image.jpg")),
 		NewInputMediaPhoto(FilePath("tests/image.jpg")),
 		NewInputMediaVideo(FilePath("tests/video.mp4")),
@@ -693,7 +699,7 @@ func TestSendWithMediaGroupPhotoVideo(t *testing.T) {
 func TestSendWithMediaGroupDocument(t *testing.T) {
 	bot := getBot(t)
 
-	cfg := NewMediaGroup(ChatID, []interface{}{
+	cfg := NewMediaGroup(ChatID, []InputMedia{
 		NewInputMediaDocument(FileURL("https://i.imgur.com/unQLJIb.jpg")),
 		NewInputMediaDocument(FilePath("tests/image.jpg")),
 	})
@@ -715,7 +721,7 @@ func TestSendWithMediaGroupDocument(t *testing.T) {
 func TestSendWithMediaGroupAudio(t *testing.T) {
 	bot := getBot(t)
 
-	cfg := NewMediaGroup(ChatID, []interface{}{
+	cfg := NewMediaGroup(ChatID, []InputMedia{
 		NewInputMediaAudio(FilePath("tests/audio.mp3")),
 		NewInputMediaAudio(FilePath("tests/audio.mp3")),
 	})
@@ -1052,7 +1058,7 @@ func TestCommands(t *testing.T) {
 // }
 
 func TestPrepareInputMediaForParams(t *testing.T) {
-	media := []interface{}{
+	media := []InputMedia{
 		NewInputMediaPhoto(FilePath("tests/image.jpg")),
 		NewInputMediaVideo(FileID("test")),
 	}
@@ -1071,3 +1077,217 @@ func TestPrepareInputMediaForParams(t *testing.T) {
 		t.Error("Passthrough value was not the same")
 	}
 }
+
+func TestPrepareInputMediaThumbs(t *testing.T) {
+	video := NewInputMediaVideo(FilePath("tests/video.mp4"))
+	video.Thumb = FilePath("tests/image.jpg")
+	animation := NewInputMediaAnimation(FileID("animation"))
+	animation.Thumb = FileBytes{Name: "thumb.jpg", Bytes: []byte("thumb")}
+
+	files := prepareInputMediaForFiles([]InputMedia{video, animation})
+	if len(files) != 3 || files[0].Name != "file-0" || files[1].Name != "file-0-thumb" || files[2].Name != "file-1-thumb" {
+		t.Fatalf("unexpected files %+v", files)
+	}
+
+	config := EditMessageMediaConfig{
+		BaseEdit: BaseEdit{ChatID: 1, MessageID: 1},
+		Media:    animation,
+	}
+
+	params, err := config.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params["media"] != `{"type":"animation","media":"animation","thumb":"attach://file-0-thumb"}` {
+		t.Fatalf("unexpected media %s", params["media"])
+	}
+}
+
+func TestMediaGroupValidation(t *testing.T) {
+	photo := NewInputMediaPhoto(FileID("photo"))
+	video := NewInputMediaVideo(FileID("video"))
+	audio := NewInputMediaAudio(FileID("audio"))
+	document := NewInputMediaDocument(FileID("document"))
+	animation := NewInputMediaAnimation(FileID("animation"))
+
+	valid := [][]InputMedia{
+		{photo, video},
+		{audio, audio},
+		{document, document, document},
+	}
+	for _, media := range valid {
+		if _, err := NewMediaGroup(int64(1), media).params(); err != nil {
+			t.Errorf("unexpected error %v", err)
+		}
+	}
+
+	invalid := [][]InputMedia{
+		{photo},
+		{photo, photo, photo, photo, photo, photo, photo, photo, photo, photo, photo},
+		{photo, animation},
+		{photo, audio},
+		{document, photo},
+		{audio, document},
+		{photo, NewInputMediaPhoto(nil)},
+	}
+	for _, media := range invalid {
+		if _, err := NewMediaGroup(int64(1), media).params(); err == nil {
+			t.Errorf("expected error for %d items", len(media))
+		}
+	}
+}
+
+// newLocalBot returns a bot talking to an httptest server driven by handler.
+// getMe is answered automatically so the constructor succeeds.
+func newLocalBot(t *testing.T, handler http.HandlerFunc) *BotAPI {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/getMe") {
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"id":1,"is_bot":true,"first_name":"test","username":"test_bot"}}`))
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	bot, err := NewBotAPIWithAPIEndpoint("token", srv.URL+"/bot%s/%s")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return bot
+}
+
+func TestMakeRequestContextCancel(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	bot := newLocalBot(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := bot.SendContext(ctx, NewMessage(int64(1), "hi"))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestUploadFilesContextCancel(t *testing.T) {
+	bot := newLocalBot(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request should not reach the server")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	pr, pw := io.Pipe()
+	defer pw.Close()
+
+	_, err := bot.RequestContext(ctx, NewDocument(int64(1), FileReader{Name: "doc.txt", Reader: pr}))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context canceled, got %v", err)
+	}
+}
+
+func TestRequestContextDelegates(t *testing.T) {
+	bot := newLocalBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/sendMessage") {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":7,"date":0,"chat":{"id":1,"type":"private"}}}`))
+	})
+
+	msg, err := bot.Send(NewMessage(int64(1), "hi"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.MessageID != 7 {
+		t.Fatalf("unexpected message id %d", msg.MessageID)
+	}
+}
+
+func TestGetUpdatesChanStop(t *testing.T) {
+	var calls atomic.Int32
+
+	bot := newLocalBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			_, _ = w.Write([]byte(`{"ok":true,"result":[{"update_id":10},{"update_id":11}]}`))
+			return
+		}
+		// Emulate a long poll that only ends when the client goes away.
+		<-r.Context().Done()
+	})
+
+	updates := bot.GetUpdatesChan(NewUpdate(0))
+
+	for _, want := range []int{10, 11} {
+		if update := <-updates; update.UpdateID != want {
+			t.Fatalf("expected update %d, got %d", want, update.UpdateID)
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		bot.StopReceivingUpdates()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("StopReceivingUpdates did not abort the long poll")
+	}
+
+	if _, ok := <-updates; ok {
+		t.Fatal("expected updates channel to be closed")
+	}
+
+	if offset := bot.UpdatesOffset(); offset != 12 {
+		t.Fatalf("expected offset 12, got %d", offset)
+	}
+
+	// Stopping twice must not panic, and polling can be restarted.
+	bot.StopReceivingUpdates()
+
+	updates = bot.GetUpdatesChan(NewUpdate(bot.UpdatesOffset()))
+	bot.StopReceivingUpdates()
+
+	if _, ok := <-updates; ok {
+		t.Fatal("expected restarted updates channel to be closed")
+	}
+}
+
+func TestGetUpdatesChanContextUnreadUpdates(t *testing.T) {
+	bot := newLocalBot(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"ok":true,"result":[{"update_id":1},{"update_id":2},{"update_id":3}]}`))
+	})
+	bot.Buffer = 0
+
+	ctx, cancel := context.WithCancel(context.Background())
+	updates := bot.GetUpdatesChanContext(ctx, NewUpdate(0))
+
+	if update := <-updates; update.UpdateID != 1 {
+		t.Fatalf("expected update 1, got %d", update.UpdateID)
+	}
+
+	// The consumer stops reading; cancelling must still close the channel.
+	cancel()
+
+	// The poller may still win a race to deliver one more update, which
+	// must then be reflected in the committed offset.
+	want := 2
+	for update := range updates {
+		want = update.UpdateID + 1
+	}
+
+	if offset := bot.UpdatesOffset(); offset != want {
+		t.Fatalf("expected offset %d, got %d", want, offset)
+	}
+}
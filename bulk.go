@@ -0,0 +1,124 @@
+package tgbotapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+)
+
+// BulkChunk is the result of one request made by a bulk operation.
+type BulkChunk struct {
+	// MessageIDs are the messages the request was made for.
+	MessageIDs []int
+	// Result holds the IDs of the messages sent by forwardMessages or
+	// copyMessages.
+	Result []MessageID
+	// Err is the error of the request, if it failed.
+	Err error
+}
+
+// BulkResult is the result of a bulk operation split into several
+// requests.
+type BulkResult struct {
+	Chunks []BulkChunk
+	// MessageIDs holds the IDs of all forwarded or copied messages, in
+	// order. Messages that could not be forwarded or copied are skipped by
+	// Telegram, so it may be shorter than the list of messages.
+	MessageIDs []MessageID
+}
+
+// Err returns the errors of the failed requests joined together, or nil.
+func (r BulkResult) Err() error {
+	var errs []error
+
+	for _, chunk := range r.Chunks {
+		if chunk.Err != nil {
+			errs = append(errs, chunk.Err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// bulkChunks sorts and deduplicates the message IDs and splits them into
+// chunks of at most MaxBulkMessageIDs.
+func bulkChunks(messageIDs []int) [][]int {
+	ids := slices.Clone(messageIDs)
+	slices.Sort(ids)
+	ids = slices.Compact(ids)
+
+	return slices.Collect(slices.Chunk(ids, MaxBulkMessageIDs))
+}
+
+// runBulk makes one request for each chunk of the message IDs, built by
+// chunkConfig, decoding the IDs of the sent messages if sends is set.
+// Failed chunks don't stop the following ones unless ctx is done.
+func (bot *BotAPI) runBulk(ctx context.Context, messageIDs []int, sends bool, chunkConfig func(ids []int) Chattable) (BulkResult, error) {
+	var result BulkResult
+
+	for _, ids := range bulkChunks(messageIDs) {
+		if err := ctx.Err(); err != nil {
+			result.Chunks = append(result.Chunks, BulkChunk{MessageIDs: ids, Err: err})
+			continue
+		}
+
+		chunk := BulkChunk{MessageIDs: ids}
+
+		resp, err := bot.RequestContext(ctx, chunkConfig(ids))
+		if err != nil {
+			chunk.Err = err
+		} else if sends {
+			chunk.Err = json.Unmarshal(resp.Result, &chunk.Result)
+			result.MessageIDs = append(result.MessageIDs, chunk.Result...)
+		}
+
+		result.Chunks = append(result.Chunks, chunk)
+	}
+
+	return result, result.Err()
+}
+
+// DeleteMessages deletes any number of messages, making one request for
+// every MaxBulkMessageIDs messages.
+func (bot *BotAPI) DeleteMessages(config DeleteMessagesConfig) (BulkResult, error) {
+	return bot.DeleteMessagesContext(context.Background(), config)
+}
+
+// DeleteMessagesContext is the same as DeleteMessages except it accepts a context.
+func (bot *BotAPI) DeleteMessagesContext(ctx context.Context, config DeleteMessagesConfig) (BulkResult, error) {
+	return bot.runBulk(ctx, config.MessageIDs, false, func(ids []int) Chattable {
+		config.MessageIDs = ids
+		return config
+	})
+}
+
+// ForwardMessages forwards any number of messages, making one request for
+// every MaxBulkMessageIDs messages. The messages are forwarded in the order
+// of their IDs.
+func (bot *BotAPI) ForwardMessages(config ForwardMessagesConfig) (BulkResult, error) {
+	return bot.ForwardMessagesContext(context.Background(), config)
+}
+
+// ForwardMessagesContext is the same as ForwardMessages except it accepts a context.
+func (bot *BotAPI) ForwardMessagesContext(ctx context.Context, config ForwardMessagesConfig) (BulkResult, error) {
+	return bot.runBulk(ctx, config.MessageIDs, true, func(ids []int) Chattable {
+		config.MessageIDs = ids
+		return config
+	})
+}
+
+// CopyMessages copies any number of messages, making one request for every
+// MaxBulkMessageIDs messages. The messages are copied in the order of their
+// IDs.
+func (bot *BotAPI) CopyMessages(config CopyMessagesConfig) (BulkResult, error) {
+	return bot.CopyMessagesContext(context.Background(), config)
+}
+
+// CopyMessagesContext is the same as CopyMessages except it accepts a context.
+func (bot *BotAPI) CopyMessagesContext(ctx context.Context, config CopyMessagesConfig) (BulkResult, error) {
+	return bot.runBulk(ctx, config.MessageIDs, true, func(ids []int) Chattable {
+		config.MessageIDs = ids
+		return config
+	})
+}
@@ -0,0 +1,145 @@
+package tgbotapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestDeleteMessagesChunks(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests [][]int
+	)
+
+	bot := newLocalBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/deleteMessages") {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+
+		var ids []int
+		if err := json.Unmarshal([]byte(r.FormValue("message_ids")), &ids); err != nil {
+			t.Error(err)
+		}
+
+		mu.Lock()
+		requests = append(requests, ids)
+		mu.Unlock()
+
+		_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+	})
+
+	ids := make([]int, 0, 260)
+	for id := 250; id > 0; id-- {
+		ids = append(ids, id)
+	}
+	ids = append(ids, 1, 2, 3, 100, 101, 250, 7, 8, 9, 10)
+
+	result, err := bot.DeleteMessages(NewDeleteMessages(ChatID, ids...))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(requests) != 3 || len(result.Chunks) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(requests))
+	}
+
+	next := 1
+	for i, chunk := range requests {
+		if !slices.IsSorted(chunk) {
+			t.Errorf("chunk %d is not sorted: %v", i, chunk)
+		}
+		if len(chunk) > MaxBulkMessageIDs {
+			t.Errorf("chunk %d has %d messages", i, len(chunk))
+		}
+		if chunk[0] != next {
+			t.Errorf("chunk %d starts at %d, expected %d", i, chunk[0], next)
+		}
+		next = chunk[len(chunk)-1] + 1
+	}
+	if next != 251 {
+		t.Errorf("expected chunks to end at 250, ended at %d", next-1)
+	}
+}
+
+func TestForwardMessagesPartialFailure(t *testing.T) {
+	bot := newLocalBot(t, func(w http.ResponseWriter, r *http.Request) {
+		var ids []int
+		if err := json.Unmarshal([]byte(r.FormValue("message_ids")), &ids); err != nil {
+			t.Error(err)
+		}
+
+		if ids[0] == 101 {
+			_, _ = w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: message to forward not found"}`))
+			return
+		}
+
+		result := make([]MessageID, len(ids))
+		for i, id := range ids {
+			result[i] = MessageID{MessageID: id + 1000}
+		}
+		data, _ := json.Marshal(result)
+		fmt.Fprintf(w, `{"ok":true,"result":%s}`, data)
+	})
+
+	ids := make([]int, 0, 250)
+	for id := 1; id <= 250; id++ {
+		ids = append(ids, id)
+	}
+
+	result, err := bot.ForwardMessages(NewForwardMessages(ChatID, ChatID, ids...))
+	if err == nil {
+		t.Fatal("expected an error for the failed chunk")
+	}
+	if result.Err() == nil {
+		t.Error("expected BulkResult.Err to report the failed chunk")
+	}
+
+	if len(result.Chunks) != 3 {
+		t.Fatalf("expected 3 chunks, got %d", len(result.Chunks))
+	}
+	if result.Chunks[0].Err != nil || result.Chunks[1].Err == nil || result.Chunks[2].Err != nil {
+		t.Errorf("unexpected chunk errors: %v, %v, %v",
+			result.Chunks[0].Err, result.Chunks[1].Err, result.Chunks[2].Err)
+	}
+
+	if len(result.MessageIDs) != 150 {
+		t.Fatalf("expected 150 forwarded messages, got %d", len(result.MessageIDs))
+	}
+	if result.MessageIDs[0].MessageID != 1001 || result.MessageIDs[100].MessageID != 1201 {
+		t.Errorf("unexpected message IDs: %v, %v", result.MessageIDs[0], result.MessageIDs[100])
+	}
+}
+
+func TestBulkMessagesConfigValidation(t *testing.T) {
+	tooMany := make([]int, MaxBulkMessageIDs+1)
+	for i := range tooMany {
+		tooMany[i] = i + 1
+	}
+
+	configs := []Chattable{
+		NewDeleteMessages(ChatID),
+		NewDeleteMessages(ChatID, tooMany...),
+		NewForwardMessages(ChatID, ChatID),
+		NewCopyMessages(ChatID, ChatID, tooMany...),
+		NewForwardMessages(ChatID, ChatID, 2, 1),
+		NewCopyMessages(ChatID, ChatID, 1, 2, 2),
+	}
+
+	for _, config := range configs {
+		if _, err := config.params(); err == nil {
+			t.Errorf("expected %s with %T to fail validation", config.method(), config)
+		}
+	}
+
+	if _, err := NewCopyMessages(ChatID, ChatID, 1, 2).params(); err != nil {
+		t.Error(err)
+	}
+	if _, err := NewDeleteMessages(ChatID, 2, 1).params(); err != nil {
+		t.Error(err)
+	}
+}
@@ -2,11 +2,15 @@ package tgbotapi
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"net/url"
 	"os"
+	"path"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 )
 
 // Telegram constants
@@ -95,6 +99,25 @@ const (
 	// UpdateTypeChatMember is when the bot must be an administrator in the chat and must explicitly specify
 	// this update in the list of allowed_updates to receive these updates.
 	UpdateTypeChatMember = "chat_member"
+
+	// UpdateTypeChatJoinRequest is request to join the chat has been sent. The bot must have the can_invite_users
+	// administrator right in the chat to receive these updates.
+	UpdateTypeChatJoinRequest = "chat_join_request"
+
+	// UpdateTypeMessageReaction is when a user changed their reaction to a message. The bot must be an
+	// administrator in the chat and must explicitly specify this update in the list of allowed_updates.
+	UpdateTypeMessageReaction = "message_reaction"
+
+	// UpdateTypeMessageReactionCount is when the anonymous reactions to a message changed. The bot must be an
+	// administrator in the chat and must explicitly specify this update in the list of allowed_updates.
+	UpdateTypeMessageReactionCount = "message_reaction_count"
+)
+
+// Reaction types
+const (
+	ReactionTypeEmoji       = "emoji"
+	ReactionTypeCustomEmoji = "custom_emoji"
+	ReactionTypePaid        = "paid"
 )
 
 // Library errors
@@ -267,13 +290,19 @@ func (CloseConfig) params() (Params, error) {
 
 // BaseChat is base type for all chat config types.
 type BaseChat struct {
-	ChatID                   int64 // required. Unique identifier for the target chat or username of the target channel (in the format @channelusername).
-	ChannelUsername          string
-	ProtectContent           bool
+	ChatID          int64 // required. Unique identifier for the target chat or username of the target channel (in the format @channelusername).
+	ChannelUsername string
+	ProtectContent  bool
+	// ReplyToMessageID and AllowSendingWithoutReply are shorthand for a
+	// ReplyParameters with these fields. They are ignored if
+	// ReplyParameters is set.
 	ReplyToMessageID         int
 	ReplyMarkup              interface{}
 	DisableNotification      bool
 	AllowSendingWithoutReply bool
+	// ReplyParameters describes the message to reply to, which may be in
+	// another chat, and the part of it to quote.
+	ReplyParameters *ReplyParameters
 	// Unique identifier of a message thread to which the message belongs; for supergroups only.
 	//
 	// In BaseChat because sendMessage, sendPhoto, sendVideo, sendAnimation, sendAudio, sendDocument, sendSticker, sendVideoNote, sendVoice, sendLocation, sendVenue, sendContact, sendDice, sendInvoice, sendGame, copyMessage, forwardMessage — used BaseChat.
@@ -285,17 +314,42 @@ func (chat *BaseChat) params() (Params, error) {
 	params := make(Params)
 
 	params.AddFirstValid("chat_id", chat.ChatID, chat.ChannelUsername)
-	params.AddNonZero("reply_to_message_id", chat.ReplyToMessageID)
 	params.AddBool("disable_notification", chat.DisableNotification)
-	params.AddBool("allow_sending_without_reply", chat.AllowSendingWithoutReply)
 	params.AddBool("protect_content", chat.ProtectContent)
 	params.AddNonZero("message_thread_id", chat.MessageThreadID)
 
+	if err := addReplyParameters(params, chat.ReplyParameters, chat.ReplyToMessageID, chat.AllowSendingWithoutReply); err != nil {
+		return params, err
+	}
+
 	err := params.AddInterface("reply_markup", chat.ReplyMarkup)
 
 	return params, err
 }
 
+// addReplyParameters adds the reply parameters, or builds them from the
+// legacy reply fields if they are nil.
+func addReplyParameters(params Params, replyParameters *ReplyParameters, replyToMessageID int, allowSendingWithoutReply bool) error {
+	if replyParameters == nil && replyToMessageID != 0 {
+		replyParameters = &ReplyParameters{
+			MessageID:                replyToMessageID,
+			AllowSendingWithoutReply: allowSendingWithoutReply,
+		}
+	}
+
+	return params.AddInterface("reply_parameters", replyParameters)
+}
+
+// addLinkPreviewOptions adds the link preview options, or disables link
+// previews if they are nil and disableWebPagePreview is set.
+func addLinkPreviewOptions(params Params, options *LinkPreviewOptions, disableWebPagePreview bool) error {
+	if options == nil && disableWebPagePreview {
+		options = &LinkPreviewOptions{IsDisabled: true}
+	}
+
+	return params.AddInterface("link_preview_options", options)
+}
+
 // BaseFile is a base type for all file config types.
 type BaseFile struct {
 	BaseChat
@@ -333,10 +387,13 @@ func (edit BaseEdit) params() (Params, error) {
 // MessageConfig contains information about a SendMessage request.
 type MessageConfig struct {
 	BaseChat
-	Text                  string
-	ParseMode             string
-	Entities              []MessageEntity
+	Text      string
+	ParseMode string
+	Entities  []MessageEntity
+	// DisableWebPagePreview is shorthand for LinkPreviewOptions with
+	// IsDisabled set. It is ignored if LinkPreviewOptions is set.
 	DisableWebPagePreview bool
+	LinkPreviewOptions    *LinkPreviewOptions
 }
 
 func (config MessageConfig) params() (Params, error) {
@@ -346,8 +403,10 @@ func (config MessageConfig) params() (Params, error) {
 	}
 
 	params.AddNonEmpty("text", config.Text)
-	params.AddBool("disable_web_page_preview", config.DisableWebPagePreview)
 	params.AddNonEmpty("parse_mode", config.ParseMode)
+	if err := addLinkPreviewOptions(params, config.LinkPreviewOptions, config.DisableWebPagePreview); err != nil {
+		return params, err
+	}
 	err = params.AddInterface("entities", config.Entities)
 
 	return params, err
@@ -411,6 +470,111 @@ func (config CopyMessageConfig) method() string {
 	return "copyMessage"
 }
 
+// MaxBulkMessageIDs is the number of messages deleteMessages,
+// forwardMessages and copyMessages accept in one request.
+const MaxBulkMessageIDs = 100
+
+// validateBulkMessageIDs checks the number of messages of a bulk request,
+// and that they are in strictly increasing order if increasing is set.
+func validateBulkMessageIDs(messageIDs []int, increasing bool) error {
+	if len(messageIDs) < 1 || len(messageIDs) > MaxBulkMessageIDs {
+		return fmt.Errorf("between 1 and %d message IDs must be given, got %d", MaxBulkMessageIDs, len(messageIDs))
+	}
+
+	if !increasing {
+		return nil
+	}
+
+	for i := 1; i < len(messageIDs); i++ {
+		if messageIDs[i] <= messageIDs[i-1] {
+			return fmt.Errorf("message IDs must be in strictly increasing order, got %d after %d", messageIDs[i], messageIDs[i-1])
+		}
+	}
+
+	return nil
+}
+
+// ForwardMessagesConfig contains information about a forwardMessages
+// request. MessageIDs must be in strictly increasing order, which
+// BotAPI.ForwardMessages takes care of.
+type ForwardMessagesConfig struct {
+	ChatID              int64
+	ChannelUsername     string
+	MessageThreadID     int
+	FromChatID          int64
+	FromChannelUsername string
+	MessageIDs          []int
+	DisableNotification bool
+	ProtectContent      bool
+}
+
+func (config ForwardMessagesConfig) params() (Params, error) {
+	params := make(Params)
+
+	if err := params.AddFirstValid("chat_id", config.ChatID, config.ChannelUsername); err != nil {
+		return params, err
+	}
+	params.AddNonZero("message_thread_id", config.MessageThreadID)
+	if err := params.AddFirstValid("from_chat_id", config.FromChatID, config.FromChannelUsername); err != nil {
+		return params, err
+	}
+	params.AddBool("disable_notification", config.DisableNotification)
+	params.AddBool("protect_content", config.ProtectContent)
+
+	if err := validateBulkMessageIDs(config.MessageIDs, true); err != nil {
+		return params, err
+	}
+	err := params.AddInterface("message_ids", config.MessageIDs)
+
+	return params, err
+}
+
+func (config ForwardMessagesConfig) method() string {
+	return "forwardMessages"
+}
+
+// CopyMessagesConfig contains information about a copyMessages request.
+// MessageIDs must be in strictly increasing order, which
+// BotAPI.CopyMessages takes care of.
+type CopyMessagesConfig struct {
+	ChatID              int64
+	ChannelUsername     string
+	MessageThreadID     int
+	FromChatID          int64
+	FromChannelUsername string
+	MessageIDs          []int
+	DisableNotification bool
+	ProtectContent      bool
+	// RemoveCaption copies the messages without their captions.
+	RemoveCaption bool
+}
+
+func (config CopyMessagesConfig) params() (Params, error) {
+	params := make(Params)
+
+	if err := params.AddFirstValid("chat_id", config.ChatID, config.ChannelUsername); err != nil {
+		return params, err
+	}
+	params.AddNonZero("message_thread_id", config.MessageThreadID)
+	if err := params.AddFirstValid("from_chat_id", config.FromChatID, config.FromChannelUsername); err != nil {
+		return params, err
+	}
+	params.AddBool("disable_notification", config.DisableNotification)
+	params.AddBool("protect_content", config.ProtectContent)
+	params.AddBool("remove_caption", config.RemoveCaption)
+
+	if err := validateBulkMessageIDs(config.MessageIDs, true); err != nil {
+		return params, err
+	}
+	err := params.AddInterface("message_ids", config.MessageIDs)
+
+	return params, err
+}
+
+func (config CopyMessagesConfig) method() string {
+	return "copyMessages"
+}
+
 // PhotoConfig contains information about a SendPhoto request.
 type PhotoConfig struct {
 	BaseFile
@@ -1007,10 +1171,13 @@ func (config ChatActionConfig) method() string {
 // EditMessageTextConfig allows you to modify the text in a message.
 type EditMessageTextConfig struct {
 	BaseEdit
-	Text                  string
-	ParseMode             string
-	Entities              []MessageEntity
+	Text      string
+	ParseMode string
+	Entities  []MessageEntity
+	// DisableWebPagePreview is shorthand for LinkPreviewOptions with
+	// IsDisabled set. It is ignored if LinkPreviewOptions is set.
 	DisableWebPagePreview bool
+	LinkPreviewOptions    *LinkPreviewOptions
 }
 
 func (config EditMessageTextConfig) params() (Params, error) {
@@ -1021,7 +1188,9 @@ func (config EditMessageTextConfig) params() (Params, error) {
 
 	params["text"] = config.Text
 	params.AddNonEmpty("parse_mode", config.ParseMode)
-	params.AddBool("disable_web_page_preview", config.DisableWebPagePreview)
+	if err := addLinkPreviewOptions(params, config.LinkPreviewOptions, config.DisableWebPagePreview); err != nil {
+		return params, err
+	}
 	err = params.AddInterface("entities", config.Entities)
 
 	return params, err
@@ -1060,7 +1229,7 @@ func (config EditMessageCaptionConfig) method() string {
 type EditMessageMediaConfig struct {
 	BaseEdit
 
-	Media interface{}
+	Media InputMedia
 }
 
 func (EditMessageMediaConfig) method() string {
@@ -1073,12 +1242,20 @@ func (config EditMessageMediaConfig) params() (Params, error) {
 		return params, err
 	}
 
+	if err := validateInputMedia(config.Media); err != nil {
+		return params, err
+	}
+
 	err = params.AddInterface("media", prepareInputMediaParam(config.Media, 0))
 
 	return params, err
 }
 
 func (config EditMessageMediaConfig) files() []RequestFile {
+	if validateInputMedia(config.Media) != nil {
+		return nil
+	}
+
 	return prepareInputMediaFile(config.Media, 0)
 }
 
@@ -1939,6 +2116,67 @@ func (config DeleteMessageConfig) params() (Params, error) {
 	return params, nil
 }
 
+// SetMessageReactionConfig changes the reactions of the bot to a message.
+type SetMessageReactionConfig struct {
+	ChatID          int64
+	ChannelUsername string
+	MessageID       int
+	// Reaction is the new list of reactions. An empty list removes the
+	// reactions of the bot.
+	Reaction []ReactionType
+	// IsBig shows the reaction with a big animation.
+	IsBig bool
+}
+
+func (config SetMessageReactionConfig) method() string {
+	return "setMessageReaction"
+}
+
+func (config SetMessageReactionConfig) params() (Params, error) {
+	params := make(Params)
+
+	if err := params.AddFirstValid("chat_id", config.ChatID, config.ChannelUsername); err != nil {
+		return params, err
+	}
+	params.AddNonZero("message_id", config.MessageID)
+	params.AddBool("is_big", config.IsBig)
+
+	reaction := config.Reaction
+	if reaction == nil {
+		reaction = []ReactionType{}
+	}
+	err := params.AddInterface("reaction", reaction)
+
+	return params, err
+}
+
+// DeleteMessagesConfig contains information about a deleteMessages request.
+// Messages that can't be deleted are skipped.
+type DeleteMessagesConfig struct {
+	ChannelUsername string
+	ChatID          int64
+	MessageIDs      []int
+}
+
+func (config DeleteMessagesConfig) method() string {
+	return "deleteMessages"
+}
+
+func (config DeleteMessagesConfig) params() (Params, error) {
+	params := make(Params)
+
+	if err := params.AddFirstValid("chat_id", config.ChatID, config.ChannelUsername); err != nil {
+		return params, err
+	}
+
+	if err := validateBulkMessageIDs(config.MessageIDs, false); err != nil {
+		return params, err
+	}
+	err := params.AddInterface("message_ids", config.MessageIDs)
+
+	return params, err
+}
+
 // PinChatMessageConfig contains information of a message in a chat to pin.
 type PinChatMessageConfig struct {
 	ChatID              int64
@@ -2097,7 +2335,13 @@ func (config GetStickerSetConfig) params() (Params, error) {
 
 // UploadStickerConfig allows you to upload a sticker for use in a set later.
 type UploadStickerConfig struct {
-	UserID     int64
+	UserID int64
+	// Sticker is the file to upload, in the .WEBP, .PNG, .TGS, or .WEBM
+	// format.
+	Sticker       RequestFileData
+	StickerFormat StickerFormat
+
+	// Deprecated: Use Sticker with StickerFormatStatic.
 	PNGSticker RequestFileData
 }
 
@@ -2110,30 +2354,61 @@ func (config UploadStickerConfig) params() (Params, error) {
 
 	params.AddNonZero64("user_id", config.UserID)
 
+	_, format := config.sticker()
+	if format == "" {
+		return params, errors.New("sticker format is required")
+	}
+	params["sticker_format"] = format.String()
+
 	return params, nil
 }
 
 func (config UploadStickerConfig) files() []RequestFile {
+	sticker, _ := config.sticker()
+
 	return []RequestFile{{
-		Name: "png_sticker",
-		Data: config.PNGSticker,
+		Name: "sticker",
+		Data: sticker,
 	}}
 }
 
+// sticker returns the file to upload and its format, taken from PNGSticker
+// if Sticker isn't set.
+func (config UploadStickerConfig) sticker() (RequestFileData, StickerFormat) {
+	if config.Sticker == nil && config.PNGSticker != nil {
+		return config.PNGSticker, StickerFormatStatic
+	}
+
+	return config.Sticker, config.StickerFormat
+}
+
 // NewStickerSetConfig allows creating a new sticker set.
-//
-// You must set either PNGSticker or TGSSticker.
 type NewStickerSetConfig struct {
-	UserID      int64
-	Name        string
-	Title       string
-	PNGSticker  RequestFileData
-	TGSSticker  RequestFileData
-	WebMSticker RequestFileData // НОВОЕ: webm_sticker
-	Emojis      string
+	UserID int64
+	Name   string
+	Title  string
+	// Stickers holds the 1-50 initial stickers of the set.
+	Stickers []InputSticker
 	// Type of stickers in the set, pass “regular”, “mask”, or “custom_emoji”. By default, a regular sticker set is created.
-	Sticker_type StickerType
+	StickerType StickerType
+	// NeedsRepainting makes custom emoji change color to the text color in
+	// messages, for custom emoji sticker sets only.
+	NeedsRepainting bool
+
+	// Deprecated: Use Stickers. If Stickers is empty, the set is created
+	// with the sticker in PNGSticker, TGSSticker or WebMSticker, associated
+	// with Emojis and MaskPosition.
+	PNGSticker RequestFileData
+	// Deprecated: Use Stickers.
+	TGSSticker RequestFileData
+	// Deprecated: Use Stickers.
+	WebMSticker RequestFileData
+	// Deprecated: Use Stickers.
+	Emojis string
+	// Deprecated: Use Stickers.
 	MaskPosition *MaskPosition
+	// Deprecated: Use StickerType.
+	Sticker_type StickerType
 }
 
 func (config NewStickerSetConfig) method() string {
@@ -2146,51 +2421,70 @@ func (config NewStickerSetConfig) params() (Params, error) {
 	params.AddNonZero64("user_id", config.UserID)
 	params["name"] = config.Name
 	params["title"] = config.Title
+	params.AddFirstValid("sticker_type", config.StickerType.String(), config.Sticker_type.String())
+	params.AddBool("needs_repainting", config.NeedsRepainting)
 
-	params["emojis"] = config.Emojis
+	inputStickers, err := config.inputStickers()
+	if err != nil {
+		return params, err
+	}
 
-	params.AddNonEmpty("sticker_type", config.Sticker_type.String())
+	if len(inputStickers) < 1 || len(inputStickers) > 50 {
+		return params, errors.New("a sticker set must be created with 1 to 50 stickers")
+	}
 
-	err := params.AddInterface("mask_position", config.MaskPosition)
+	stickers := make([]InputSticker, len(inputStickers))
+	for idx, sticker := range inputStickers {
+		if err := validateInputSticker(sticker); err != nil {
+			return params, err
+		}
 
-	n := 0
-	if config.PNGSticker != nil {
-		n++
-	}
-	if config.TGSSticker != nil {
-		n++
-	}
-	if config.WebMSticker != nil {
-		n++
-	}
-	if n != 1 {
-		err = fmt.Errorf("exactly one of png_sticker, tgs_sticker or webm_sticker must be provided")
+		stickers[idx] = prepareInputStickerParam(sticker, idx)
 	}
 
+	err = params.AddInterface("stickers", stickers)
+
 	return params, err
 }
 
 func (config NewStickerSetConfig) files() []RequestFile {
-	if config.PNGSticker != nil {
-		return []RequestFile{{Name: "png_sticker", Data: config.PNGSticker}}
-	}
-	if config.TGSSticker != nil {
-		return []RequestFile{{Name: "tgs_sticker", Data: config.TGSSticker}}
+	files := []RequestFile{}
+
+	stickers, _ := config.inputStickers()
+	for idx, sticker := range stickers {
+		files = append(files, prepareInputStickerFile(sticker, idx)...)
 	}
-	if config.WebMSticker != nil {
-		return []RequestFile{{Name: "webm_sticker", Data: config.WebMSticker}}
+
+	return files
+}
+
+// inputStickers returns Stickers, or the sticker described by the
+// deprecated fields if it is empty.
+func (config NewStickerSetConfig) inputStickers() ([]InputSticker, error) {
+	if len(config.Stickers) > 0 {
+		return config.Stickers, nil
 	}
-	return nil
+
+	return legacyInputStickers(config.PNGSticker, config.TGSSticker, config.WebMSticker, config.Emojis, config.MaskPosition)
 }
 
 // AddStickerConfig allows you to add a sticker to a set.
 type AddStickerConfig struct {
-	UserID       int64
-	Name         string
-	PNGSticker   RequestFileData
-	TGSSticker   RequestFileData
-	WebMSticker  RequestFileData
-	Emojis       string
+	UserID  int64
+	Name    string
+	Sticker InputSticker
+
+	// Deprecated: Use Sticker. If Sticker has no file, the sticker in
+	// PNGSticker, TGSSticker or WebMSticker is added, associated with Emojis
+	// and MaskPosition.
+	PNGSticker RequestFileData
+	// Deprecated: Use Sticker.
+	TGSSticker RequestFileData
+	// Deprecated: Use Sticker.
+	WebMSticker RequestFileData
+	// Deprecated: Use Sticker.
+	Emojis string
+	// Deprecated: Use Sticker.
 	MaskPosition *MaskPosition
 }
 
@@ -2203,38 +2497,74 @@ func (config AddStickerConfig) params() (Params, error) {
 
 	params.AddNonZero64("user_id", config.UserID)
 	params["name"] = config.Name
-	params["emojis"] = config.Emojis
-
-	err := params.AddInterface("mask_position", config.MaskPosition)
 
-	n := 0
-	if config.PNGSticker != nil {
-		n++
-	}
-	if config.TGSSticker != nil {
-		n++
-	}
-	if config.WebMSticker != nil {
-		n++
+	sticker, err := config.inputSticker()
+	if err != nil {
+		return params, err
 	}
-	if n != 1 {
-		err = fmt.Errorf("exactly one of png_sticker, tgs_sticker or webm_sticker must be provided")
+
+	if err := validateInputSticker(sticker); err != nil {
+		return params, err
 	}
 
+	err = params.AddInterface("sticker", prepareInputStickerParam(sticker, 0))
+
 	return params, err
 }
 
 func (config AddStickerConfig) files() []RequestFile {
-	if config.PNGSticker != nil {
-		return []RequestFile{{Name: "png_sticker", Data: config.PNGSticker}}
+	sticker, _ := config.inputSticker()
+
+	return prepareInputStickerFile(sticker, 0)
+}
+
+// inputSticker returns Sticker, or the sticker described by the deprecated
+// fields if it has no file.
+func (config AddStickerConfig) inputSticker() (InputSticker, error) {
+	if config.Sticker.Sticker != nil {
+		return config.Sticker, nil
 	}
-	if config.TGSSticker != nil {
-		return []RequestFile{{Name: "tgs_sticker", Data: config.TGSSticker}}
+
+	stickers, err := legacyInputStickers(config.PNGSticker, config.TGSSticker, config.WebMSticker, config.Emojis, config.MaskPosition)
+	if err != nil || len(stickers) == 0 {
+		return config.Sticker, err
 	}
-	if config.WebMSticker != nil {
-		return []RequestFile{{Name: "webm_sticker", Data: config.WebMSticker}}
+
+	return stickers[0], nil
+}
+
+// ReplaceStickerInSetConfig allows you to replace a sticker in a set with a
+// new one, keeping its position.
+type ReplaceStickerInSetConfig struct {
+	UserID int64
+	Name   string
+	// OldSticker is the file ID of the sticker to replace.
+	OldSticker string
+	Sticker    InputSticker
+}
+
+func (config ReplaceStickerInSetConfig) method() string {
+	return "replaceStickerInSet"
+}
+
+func (config ReplaceStickerInSetConfig) params() (Params, error) {
+	params := make(Params)
+
+	params.AddNonZero64("user_id", config.UserID)
+	params["name"] = config.Name
+	params["old_sticker"] = config.OldSticker
+
+	if err := validateInputSticker(config.Sticker); err != nil {
+		return params, err
 	}
-	return nil
+
+	err := params.AddInterface("sticker", prepareInputStickerParam(config.Sticker, 0))
+
+	return params, err
+}
+
+func (config ReplaceStickerInSetConfig) files() []RequestFile {
+	return prepareInputStickerFile(config.Sticker, 0)
 }
 
 // SetStickerPositionConfig allows you to change the position of a sticker in a set.
@@ -2273,31 +2603,200 @@ func (config DeleteStickerConfig) params() (Params, error) {
 	return params, nil
 }
 
+// SetStickerEmojiListConfig allows you to change the emoji of a sticker.
+type SetStickerEmojiListConfig struct {
+	Sticker   string
+	EmojiList []string
+}
+
+func (config SetStickerEmojiListConfig) method() string {
+	return "setStickerEmojiList"
+}
+
+func (config SetStickerEmojiListConfig) params() (Params, error) {
+	params := make(Params)
+
+	params["sticker"] = config.Sticker
+	err := params.AddInterface("emoji_list", config.EmojiList)
+
+	return params, err
+}
+
+// SetStickerKeywordsConfig allows you to change the search keywords of a
+// regular or custom emoji sticker.
+type SetStickerKeywordsConfig struct {
+	Sticker  string
+	Keywords []string
+}
+
+func (config SetStickerKeywordsConfig) method() string {
+	return "setStickerKeywords"
+}
+
+func (config SetStickerKeywordsConfig) params() (Params, error) {
+	params := make(Params)
+
+	params["sticker"] = config.Sticker
+	err := params.AddInterface("keywords", config.Keywords)
+
+	return params, err
+}
+
+// SetStickerMaskPositionConfig allows you to change the position of a mask
+// sticker. A nil MaskPosition removes it.
+type SetStickerMaskPositionConfig struct {
+	Sticker      string
+	MaskPosition *MaskPosition
+}
+
+func (config SetStickerMaskPositionConfig) method() string {
+	return "setStickerMaskPosition"
+}
+
+func (config SetStickerMaskPositionConfig) params() (Params, error) {
+	params := make(Params)
+
+	params["sticker"] = config.Sticker
+	err := params.AddInterface("mask_position", config.MaskPosition)
+
+	return params, err
+}
+
+// SetStickerSetTitleConfig allows you to change the title of a sticker set.
+type SetStickerSetTitleConfig struct {
+	Name  string
+	Title string
+}
+
+func (config SetStickerSetTitleConfig) method() string {
+	return "setStickerSetTitle"
+}
+
+func (config SetStickerSetTitleConfig) params() (Params, error) {
+	params := make(Params)
+
+	params["name"] = config.Name
+	params["title"] = config.Title
+
+	return params, nil
+}
+
+// SetStickerSetThumbnailConfig allows you to set the thumbnail for a sticker
+// set. A nil Thumbnail removes it.
+type SetStickerSetThumbnailConfig struct {
+	Name      string
+	UserID    int64
+	Thumbnail RequestFileData
+	// Format of the thumbnail, which must match the format of the stickers
+	// in the set.
+	Format StickerFormat
+}
+
+func (config SetStickerSetThumbnailConfig) method() string {
+	return "setStickerSetThumbnail"
+}
+
+func (config SetStickerSetThumbnailConfig) params() (Params, error) {
+	params := make(Params)
+
+	params["name"] = config.Name
+	params.AddNonZero64("user_id", config.UserID)
+
+	if config.Format == "" {
+		return params, errors.New("thumbnail format is required")
+	}
+	params["format"] = config.Format.String()
+
+	return params, nil
+}
+
+func (config SetStickerSetThumbnailConfig) files() []RequestFile {
+	if config.Thumbnail == nil {
+		return nil
+	}
+
+	return []RequestFile{{
+		Name: "thumbnail",
+		Data: config.Thumbnail,
+	}}
+}
+
 // SetStickerSetThumbConfig allows you to set the thumbnail for a sticker set.
+//
+// Deprecated: Use SetStickerSetThumbnailConfig, which this config is sent
+// as.
 type SetStickerSetThumbConfig struct {
 	Name   string
 	UserID int64
 	Thumb  RequestFileData
+	// Format of the thumbnail. If empty, it is guessed from the extension of
+	// the file name of Thumb.
+	Format StickerFormat
 }
 
 func (config SetStickerSetThumbConfig) method() string {
-	return "setStickerSetThumb"
+	return config.thumbnailConfig().method()
 }
 
 func (config SetStickerSetThumbConfig) params() (Params, error) {
+	return config.thumbnailConfig().params()
+}
+
+func (config SetStickerSetThumbConfig) files() []RequestFile {
+	return config.thumbnailConfig().files()
+}
+
+func (config SetStickerSetThumbConfig) thumbnailConfig() SetStickerSetThumbnailConfig {
+	format := config.Format
+	if format == "" {
+		format = stickerFormatOf(config.Thumb)
+	}
+
+	return SetStickerSetThumbnailConfig{
+		Name:      config.Name,
+		UserID:    config.UserID,
+		Thumbnail: config.Thumb,
+		Format:    format,
+	}
+}
+
+// SetCustomEmojiStickerSetThumbnailConfig allows you to set the thumbnail of
+// a custom emoji sticker set to one of its custom emoji. An empty
+// CustomEmojiID uses the first sticker of the set.
+type SetCustomEmojiStickerSetThumbnailConfig struct {
+	Name          string
+	CustomEmojiID string
+}
+
+func (config SetCustomEmojiStickerSetThumbnailConfig) method() string {
+	return "setCustomEmojiStickerSetThumbnail"
+}
+
+func (config SetCustomEmojiStickerSetThumbnailConfig) params() (Params, error) {
 	params := make(Params)
 
 	params["name"] = config.Name
-	params.AddNonZero64("user_id", config.UserID)
+	params.AddNonEmpty("custom_emoji_id", config.CustomEmojiID)
 
 	return params, nil
 }
 
-func (config SetStickerSetThumbConfig) files() []RequestFile {
-	return []RequestFile{{
-		Name: "thumb",
-		Data: config.Thumb,
-	}}
+// DeleteStickerSetConfig allows you to delete a sticker set created by the
+// bot.
+type DeleteStickerSetConfig struct {
+	Name string
+}
+
+func (config DeleteStickerSetConfig) method() string {
+	return "deleteStickerSet"
+}
+
+func (config DeleteStickerSetConfig) params() (Params, error) {
+	params := make(Params)
+
+	params["name"] = config.Name
+
+	return params, nil
 }
 
 // SetChatStickerSetConfig allows you to set the sticker set for a supergroup.
@@ -2346,10 +2845,13 @@ type MediaGroupConfig struct {
 	ChatID          int64
 	ChannelUsername string
 
-	Media               []interface{}
+	Media               []InputMedia
 	DisableNotification bool
-	ReplyToMessageID    int
-	MessageThreadID     int
+	// ReplyToMessageID is shorthand for ReplyParameters with this message.
+	// It is ignored if ReplyParameters is set.
+	ReplyToMessageID int
+	ReplyParameters  *ReplyParameters
+	MessageThreadID  int
 }
 
 func (config MediaGroupConfig) method() string {
@@ -2361,15 +2863,26 @@ func (config MediaGroupConfig) params() (Params, error) {
 
 	params.AddFirstValid("chat_id", config.ChatID, config.ChannelUsername)
 	params.AddBool("disable_notification", config.DisableNotification)
-	params.AddNonZero("reply_to_message_id", config.ReplyToMessageID)
 	params.AddNonZero("message_thread_id", config.MessageThreadID)
 
+	if err := addReplyParameters(params, config.ReplyParameters, config.ReplyToMessageID, false); err != nil {
+		return params, err
+	}
+
+	if err := validateMediaGroup(config.Media); err != nil {
+		return params, err
+	}
+
 	err := params.AddInterface("media", prepareInputMediaForParams(config.Media))
 
 	return params, err
 }
 
 func (config MediaGroupConfig) files() []RequestFile {
+	if validateMediaGroup(config.Media) != nil {
+		return nil
+	}
+
 	return prepareInputMediaForFiles(config.Media)
 }
 
@@ -2459,6 +2972,121 @@ func (config DeleteMyCommandsConfig) params() (Params, error) {
 	return params, err
 }
 
+// SetMyNameConfig changes the bot's name for users with the language, or
+// for all users without a dedicated name if LanguageCode is empty. An empty
+// Name removes it.
+type SetMyNameConfig struct {
+	Name         string
+	LanguageCode string
+}
+
+func (config SetMyNameConfig) method() string {
+	return "setMyName"
+}
+
+func (config SetMyNameConfig) params() (Params, error) {
+	params := make(Params)
+
+	params.AddNonEmpty("name", config.Name)
+	params.AddNonEmpty("language_code", config.LanguageCode)
+
+	return params, nil
+}
+
+// GetMyNameConfig gets the bot's name for the language.
+type GetMyNameConfig struct {
+	LanguageCode string
+}
+
+func (config GetMyNameConfig) method() string {
+	return "getMyName"
+}
+
+func (config GetMyNameConfig) params() (Params, error) {
+	params := make(Params)
+
+	params.AddNonEmpty("language_code", config.LanguageCode)
+
+	return params, nil
+}
+
+// SetMyDescriptionConfig changes the bot's description for users with the
+// language, or for all users without a dedicated description if
+// LanguageCode is empty. An empty Description removes it.
+type SetMyDescriptionConfig struct {
+	Description  string
+	LanguageCode string
+}
+
+func (config SetMyDescriptionConfig) method() string {
+	return "setMyDescription"
+}
+
+func (config SetMyDescriptionConfig) params() (Params, error) {
+	params := make(Params)
+
+	params.AddNonEmpty("description", config.Description)
+	params.AddNonEmpty("language_code", config.LanguageCode)
+
+	return params, nil
+}
+
+// GetMyDescriptionConfig gets the bot's description for the language.
+type GetMyDescriptionConfig struct {
+	LanguageCode string
+}
+
+func (config GetMyDescriptionConfig) method() string {
+	return "getMyDescription"
+}
+
+func (config GetMyDescriptionConfig) params() (Params, error) {
+	params := make(Params)
+
+	params.AddNonEmpty("language_code", config.LanguageCode)
+
+	return params, nil
+}
+
+// SetMyShortDescriptionConfig changes the bot's short description for users
+// with the language, or for all users without a dedicated short description
+// if LanguageCode is empty. An empty ShortDescription removes it.
+type SetMyShortDescriptionConfig struct {
+	ShortDescription string
+	LanguageCode     string
+}
+
+func (config SetMyShortDescriptionConfig) method() string {
+	return "setMyShortDescription"
+}
+
+func (config SetMyShortDescriptionConfig) params() (Params, error) {
+	params := make(Params)
+
+	params.AddNonEmpty("short_description", config.ShortDescription)
+	params.AddNonEmpty("language_code", config.LanguageCode)
+
+	return params, nil
+}
+
+// GetMyShortDescriptionConfig gets the bot's short description for the
+// language.
+type GetMyShortDescriptionConfig struct {
+	LanguageCode string
+}
+
+func (config GetMyShortDescriptionConfig) method() string {
+	return "getMyShortDescription"
+}
+
+func (config GetMyShortDescriptionConfig) params() (Params, error) {
+	params := make(Params)
+
+	params.AddNonEmpty("language_code", config.LanguageCode)
+
+	return params, nil
+}
+
 // SetChatMenuButtonConfig changes the bot's menu button in a private chat,
 // or the default menu button.
 type SetChatMenuButtonConfig struct {
@@ -2534,57 +3162,27 @@ func (config GetMyDefaultAdministratorRightsConfig) params() (Params, error) {
 	return params, nil
 }
 
-// prepareInputMediaParam evaluates a single InputMedia and determines if it
-// needs to be modified for a successful upload. If it returns nil, then the
-// value does not need to be included in the params. Otherwise, it will return
-// the same type as was originally provided.
+// prepareInputMediaParam evaluates a single InputMedia and replaces the files
+// that need to be uploaded with references to them. The original media is
+// not modified.
 //
 // The idx is used to calculate the file field name. If you only have a single
 // file, 0 may be used. It is formatted into "attach://file-%d" for the primary
 // media and "attach://file-%d-thumb" for thumbnails.
 //
 // It is expected to be used in conjunction with prepareInputMediaFile.
-func prepareInputMediaParam(inputMedia interface{}, idx int) interface{} {
-	switch m := inputMedia.(type) {
-	case InputMediaPhoto:
-		if m.Media.NeedsUpload() {
-			m.Media = fileAttach(fmt.Sprintf("attach://file-%d", idx))
-		}
-
-		return m
-	case InputMediaVideo:
-		if m.Media.NeedsUpload() {
-			m.Media = fileAttach(fmt.Sprintf("attach://file-%d", idx))
-		}
+func prepareInputMediaParam(inputMedia InputMedia, idx int) InputMedia {
+	media, thumb := inputMedia.mediaFiles()
 
-		if m.Thumb != nil && m.Thumb.NeedsUpload() {
-			m.Thumb = fileAttach(fmt.Sprintf("attach://file-%d-thumb", idx))
-		}
-
-		return m
-	case InputMediaAudio:
-		if m.Media.NeedsUpload() {
-			m.Media = fileAttach(fmt.Sprintf("attach://file-%d", idx))
-		}
-
-		if m.Thumb != nil && m.Thumb.NeedsUpload() {
-			m.Thumb = fileAttach(fmt.Sprintf("attach://file-%d-thumb", idx))
-		}
-
-		return m
-	case InputMediaDocument:
-		if m.Media.NeedsUpload() {
-			m.Media = fileAttach(fmt.Sprintf("attach://file-%d", idx))
-		}
-
-		if m.Thumb != nil && m.Thumb.NeedsUpload() {
-			m.Thumb = fileAttach(fmt.Sprintf("attach://file-%d-thumb", idx))
-		}
+	if media.NeedsUpload() {
+		media = fileAttach(fmt.Sprintf("attach://file-%d", idx))
+	}
 
-		return m
+	if thumb != nil && thumb.NeedsUpload() {
+		thumb = fileAttach(fmt.Sprintf("attach://file-%d-thumb", idx))
 	}
 
-	return nil
+	return inputMedia.withFiles(media, thumb)
 }
 
 // prepareInputMediaFile generates an array of RequestFile to provide for
@@ -2595,59 +3193,23 @@ func prepareInputMediaParam(inputMedia interface{}, idx int) interface{} {
 // "file-%d" for the main file and "file-%d-thumb" for the thumbnail.
 //
 // It is expected to be used in conjunction with prepareInputMediaParam.
-func prepareInputMediaFile(inputMedia interface{}, idx int) []RequestFile {
+func prepareInputMediaFile(inputMedia InputMedia, idx int) []RequestFile {
 	files := []RequestFile{}
 
-	switch m := inputMedia.(type) {
-	case InputMediaPhoto:
-		if m.Media.NeedsUpload() {
-			files = append(files, RequestFile{
-				Name: fmt.Sprintf("file-%d", idx),
-				Data: m.Media,
-			})
-		}
-	case InputMediaVideo:
-		if m.Media.NeedsUpload() {
-			files = append(files, RequestFile{
-				Name: fmt.Sprintf("file-%d", idx),
-				Data: m.Media,
-			})
-		}
+	media, thumb := inputMedia.mediaFiles()
 
-		if m.Thumb != nil && m.Thumb.NeedsUpload() {
-			files = append(files, RequestFile{
-				Name: fmt.Sprintf("file-%d", idx),
-				Data: m.Thumb,
-			})
-		}
-	case InputMediaDocument:
-		if m.Media.NeedsUpload() {
-			files = append(files, RequestFile{
-				Name: fmt.Sprintf("file-%d", idx),
-				Data: m.Media,
-			})
-		}
-
-		if m.Thumb != nil && m.Thumb.NeedsUpload() {
-			files = append(files, RequestFile{
-				Name: fmt.Sprintf("file-%d", idx),
-				Data: m.Thumb,
-			})
-		}
-	case InputMediaAudio:
-		if m.Media.NeedsUpload() {
-			files = append(files, RequestFile{
-				Name: fmt.Sprintf("file-%d", idx),
-				Data: m.Media,
-			})
-		}
+	if media.NeedsUpload() {
+		files = append(files, RequestFile{
+			Name: fmt.Sprintf("file-%d", idx),
+			Data: media,
+		})
+	}
 
-		if m.Thumb != nil && m.Thumb.NeedsUpload() {
-			files = append(files, RequestFile{
-				Name: fmt.Sprintf("file-%d", idx),
-				Data: m.Thumb,
-			})
-		}
+	if thumb != nil && thumb.NeedsUpload() {
+		files = append(files, RequestFile{
+			Name: fmt.Sprintf("file-%d-thumb", idx),
+			Data: thumb,
+		})
 	}
 
 	return files
@@ -2658,14 +3220,11 @@ func prepareInputMediaFile(inputMedia interface{}, idx int) []RequestFile {
 //
 // It is expected that files will get data from the associated function,
 // prepareInputMediaForFiles.
-func prepareInputMediaForParams(inputMedia []interface{}) []interface{} {
-	newMedia := make([]interface{}, len(inputMedia))
-	copy(newMedia, inputMedia)
+func prepareInputMediaForParams(inputMedia []InputMedia) []InputMedia {
+	newMedia := make([]InputMedia, len(inputMedia))
 
 	for idx, media := range inputMedia {
-		if param := prepareInputMediaParam(media, idx); param != nil {
-			newMedia[idx] = param
-		}
+		newMedia[idx] = prepareInputMediaParam(media, idx)
 	}
 
 	return newMedia
@@ -2676,18 +3235,191 @@ func prepareInputMediaForParams(inputMedia []interface{}) []interface{} {
 //
 // It is expected that params will get data from the associated function,
 // prepareInputMediaForParams.
-func prepareInputMediaForFiles(inputMedia []interface{}) []RequestFile {
+func prepareInputMediaForFiles(inputMedia []InputMedia) []RequestFile {
 	files := []RequestFile{}
 
 	for idx, media := range inputMedia {
-		if file := prepareInputMediaFile(media, idx); file != nil {
-			files = append(files, file...)
-		}
+		files = append(files, prepareInputMediaFile(media, idx)...)
 	}
 
 	return files
 }
 
+// validateInputSticker checks that a sticker has a file, a format and 1-20
+// emoji.
+func validateInputSticker(sticker InputSticker) error {
+	if sticker.Sticker == nil {
+		return errors.New("input sticker has no file")
+	}
+
+	if sticker.Format == "" {
+		return errors.New("input sticker has no format")
+	}
+
+	if len(sticker.EmojiList) < 1 || len(sticker.EmojiList) > 20 {
+		return errors.New("input sticker must have 1 to 20 emoji")
+	}
+
+	return nil
+}
+
+// legacyInputStickers returns the sticker described by the fields used
+// before Bot API 6.6, if one of the files is set.
+func legacyInputStickers(png, tgs, webm RequestFileData, emojis string, mask *MaskPosition) ([]InputSticker, error) {
+	var stickers []InputSticker
+
+	for _, file := range []struct {
+		data   RequestFileData
+		format StickerFormat
+	}{
+		{png, StickerFormatStatic},
+		{tgs, StickerFormatAnimated},
+		{webm, StickerFormatVideo},
+	} {
+		if file.data != nil {
+			stickers = append(stickers, InputSticker{
+				Sticker:      file.data,
+				Format:       file.format,
+				EmojiList:    splitEmoji(emojis),
+				MaskPosition: mask,
+			})
+		}
+	}
+
+	if len(stickers) > 1 {
+		return nil, errors.New("exactly one of png_sticker, tgs_sticker or webm_sticker must be provided")
+	}
+
+	return stickers, nil
+}
+
+// splitEmoji splits a string of emoji, as sent before Bot API 6.6, into
+// single emoji, keeping modifiers, joined sequences and flags together.
+func splitEmoji(s string) []string {
+	var emoji []string
+
+	joinNext := false
+	for _, r := range s {
+		last := len(emoji) - 1
+
+		switch {
+		case last < 0:
+			emoji = append(emoji, string(r))
+		case joinNext,
+			r == '\u200d',                  // zero width joiner
+			r == '\ufe0e' || r == '\ufe0f', // variation selectors
+			r == '\u20e3',                  // combining keycap
+			r >= 0x1f3fb && r <= 0x1f3ff,   // skin tones
+			r >= 0xe0020 && r <= 0xe007f,   // tags
+			isRegionalIndicator(r) && utf8.RuneCountInString(emoji[last]) == 1 &&
+				isRegionalIndicator([]rune(emoji[last])[0]):
+			emoji[last] += string(r)
+		default:
+			emoji = append(emoji, string(r))
+		}
+
+		joinNext = r == '\u200d'
+	}
+
+	return emoji
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+// stickerFormatOf guesses the format of a sticker file from the extension
+// of its name, returning an empty format if it can't.
+func stickerFormatOf(file RequestFileData) StickerFormat {
+	var name string
+
+	switch data := file.(type) {
+	case FileBytes:
+		name = data.Name
+	case FileReader:
+		name = data.Name
+	case FilePath:
+		name = string(data)
+	case FileURL:
+		name = string(data)
+	}
+
+	switch strings.ToLower(path.Ext(name)) {
+	case ".png", ".webp":
+		return StickerFormatStatic
+	case ".tgs":
+		return StickerFormatAnimated
+	case ".webm":
+		return StickerFormatVideo
+	default:
+		return ""
+	}
+}
+
+// prepareInputStickerParam replaces a sticker file that needs to be uploaded
+// with "attach://sticker-%d", like prepareInputMediaParam.
+func prepareInputStickerParam(sticker InputSticker, idx int) InputSticker {
+	if sticker.Sticker != nil && sticker.Sticker.NeedsUpload() {
+		sticker.Sticker = fileAttach(fmt.Sprintf("attach://sticker-%d", idx))
+	}
+
+	return sticker
+}
+
+// prepareInputStickerFile returns the file of a sticker that needs to be
+// uploaded as "sticker-%d", like prepareInputMediaFile.
+func prepareInputStickerFile(sticker InputSticker, idx int) []RequestFile {
+	if sticker.Sticker == nil || !sticker.Sticker.NeedsUpload() {
+		return []RequestFile{}
+	}
+
+	return []RequestFile{{
+		Name: fmt.Sprintf("sticker-%d", idx),
+		Data: sticker.Sticker,
+	}}
+}
+
+// validateInputMedia checks that the media has a file to send.
+func validateInputMedia(media InputMedia) error {
+	if media == nil {
+		return errors.New("media is required")
+	}
+
+	if file, _ := media.mediaFiles(); file == nil {
+		return fmt.Errorf("%s has no media file", media.mediaType())
+	}
+
+	return nil
+}
+
+// validateMediaGroup checks the media against the rules Telegram applies to
+// media groups: 2 to 10 items, no animations, and audio files and documents
+// only grouped with media of the same type.
+func validateMediaGroup(media []InputMedia) error {
+	if len(media) < 2 || len(media) > 10 {
+		return fmt.Errorf("media group must have 2 to 10 items, got %d", len(media))
+	}
+
+	for _, m := range media {
+		if err := validateInputMedia(m); err != nil {
+			return err
+		}
+	}
+
+	first := media[0].mediaType()
+
+	for _, m := range media {
+		switch t := m.mediaType(); {
+		case t == "animation":
+			return errors.New("media group can't contain animations")
+		case (first == "audio" || first == "document" || t == "audio" || t == "document") && t != first:
+			return fmt.Errorf("media group can't mix %s with %s", first, t)
+		}
+	}
+
+	return nil
+}
+
 // GetCustomEmojiStickersConfig — for methods getCustomEmojiStickers.
 // custom_emoji_ids: must be an array, maximum 200 custom emoji identifiers.
 type GetCustomEmojiStickersConfig struct {
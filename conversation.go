@@ -0,0 +1,373 @@
+package tgbotapi
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ConversationKey identifies a conversation with a user in a chat.
+type ConversationKey struct {
+	ChatID int64
+	UserID int64
+}
+
+// ConversationKeyFor returns the key of the conversation an update belongs
+// to. Updates without a sender have no conversation.
+func ConversationKeyFor(update Update) (ConversationKey, bool) {
+	user := update.SentFrom()
+	if user == nil {
+		return ConversationKey{}, false
+	}
+
+	key := ConversationKey{UserID: user.ID}
+	if chat := update.FromChat(); chat != nil {
+		key.ChatID = chat.ID
+	}
+
+	return key, true
+}
+
+// ConversationFrame is an active conversation and its current state.
+type ConversationFrame struct {
+	Conversation string `json:"conversation"`
+	State        string `json:"state"`
+}
+
+// ConversationState is the stored state of a conversation.
+type ConversationState struct {
+	// Stack holds the active conversations, the innermost last.
+	Stack []ConversationFrame `json:"stack"`
+	// Data holds the values collected during the conversation.
+	Data map[string]string `json:"data,omitempty"`
+	// Expires is when the conversation times out, if it has a timeout.
+	Expires time.Time `json:"expires,omitempty"`
+}
+
+// ConversationStore stores the state of conversations.
+type ConversationStore interface {
+	Load(ctx context.Context, key ConversationKey) (state ConversationState, ok bool, err error)
+	Save(ctx context.Context, key ConversationKey, state ConversationState) error
+	Delete(ctx context.Context, key ConversationKey) error
+}
+
+// MemoryConversationStore is a ConversationStore keeping conversations in
+// memory, so they are lost when the program exits.
+type MemoryConversationStore struct {
+	mu     sync.Mutex
+	states map[ConversationKey]ConversationState
+}
+
+// NewMemoryConversationStore creates an empty MemoryConversationStore.
+func NewMemoryConversationStore() *MemoryConversationStore {
+	return &MemoryConversationStore{states: make(map[ConversationKey]ConversationState)}
+}
+
+// Load returns the state of the conversation.
+func (s *MemoryConversationStore) Load(ctx context.Context, key ConversationKey) (ConversationState, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.states[key]
+
+	return state, ok, nil
+}
+
+// Save stores the state of the conversation.
+func (s *MemoryConversationStore) Save(ctx context.Context, key ConversationKey, state ConversationState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.states[key] = state
+
+	return nil
+}
+
+// Delete forgets the conversation.
+func (s *MemoryConversationStore) Delete(ctx context.Context, key ConversationKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.states, key)
+
+	return nil
+}
+
+// StateHandler handles an update in a conversation. It moves the
+// conversation along with the methods of c.
+type StateHandler func(ctx context.Context, c *ConversationContext, update Update) error
+
+// ConversationContext is passed to the handlers of a conversation.
+type ConversationContext struct {
+	Bot *BotAPI
+	Key ConversationKey
+	// Data holds the values collected during the conversation. It is shared
+	// with nested conversations and stored with the conversation.
+	Data map[string]string
+
+	state string
+
+	next        string
+	nextSet     bool
+	ended       bool
+	sub         *Conversation
+	returnState string
+}
+
+// State returns the current state.
+func (c *ConversationContext) State() string {
+	return c.state
+}
+
+// Next sets the state handling the next update.
+func (c *ConversationContext) Next(state string) {
+	c.next = state
+	c.nextSet = true
+}
+
+// End ends the conversation. A nested conversation returns to its parent.
+func (c *ConversationContext) End() {
+	c.ended = true
+}
+
+// Begin starts a nested conversation, which must have been added with Sub.
+// Its start handler is called with the current update, and once it ends the
+// handler of returnState is called in this conversation.
+func (c *ConversationContext) Begin(sub *Conversation, returnState string) {
+	c.sub = sub
+	c.returnState = returnState
+}
+
+// Conversation routes the updates of a user in a chat through a series of
+// states, such as asking for a name and then a phone number.
+//
+// Conversations start with an update matching the entry predicate. Updates
+// of a user with an active conversation are handled by the handler of its
+// current state. The updates of a user should be handled one at a time, for
+// example by a WorkerPool using KeyByChat.
+type Conversation struct {
+	// Name identifies the conversation in the stored state.
+	Name string
+	// Timeout ends conversations without updates for this long, if not zero.
+	// Timeouts are noticed when the next update arrives, which is then
+	// passed to OnTimeout.
+	Timeout time.Duration
+	// OnTimeout is called when a conversation timed out.
+	OnTimeout StateHandler
+	// CancelCommands end the conversation when sent, given without the
+	// leading slash.
+	CancelCommands []string
+	// OnCancel is called when a conversation is cancelled.
+	OnCancel StateHandler
+
+	store  ConversationStore
+	entry  Predicate
+	start  StateHandler
+	states map[string]StateHandler
+	subs   map[string]*Conversation
+}
+
+// NewConversation creates a conversation storing its state in store.
+func NewConversation(name string, store ConversationStore) *Conversation {
+	return &Conversation{
+		Name:   name,
+		store:  store,
+		states: make(map[string]StateHandler),
+		subs:   make(map[string]*Conversation),
+	}
+}
+
+// Entry sets the predicate starting the conversation and the handler called
+// when it starts, which usually asks the first question and calls Next.
+//
+// A nested conversation only needs the start handler, the predicate may be
+// nil.
+func (conv *Conversation) Entry(predicate Predicate, start StateHandler) {
+	conv.entry = predicate
+	conv.start = start
+}
+
+// State sets the handler of a state.
+func (conv *Conversation) State(state string, handler StateHandler) {
+	conv.states[state] = handler
+}
+
+// Sub adds a conversation that can be nested in this one with Begin.
+func (conv *Conversation) Sub(sub *Conversation) {
+	conv.subs[sub.Name] = sub
+}
+
+// find returns the conversation with the name among this one and the ones
+// nested in it.
+func (conv *Conversation) find(name string) *Conversation {
+	if conv.Name == name {
+		return conv
+	}
+
+	for _, sub := range conv.subs {
+		if found := sub.find(name); found != nil {
+			return found
+		}
+	}
+
+	return nil
+}
+
+// Matches reports whether the conversation handles the update, because the
+// user is in the conversation or the update starts it.
+func (conv *Conversation) Matches(update Update) bool {
+	key, ok := ConversationKeyFor(update)
+	if !ok {
+		return false
+	}
+
+	state, ok, err := conv.store.Load(context.Background(), key)
+	if err != nil {
+		log.Printf("Failed to load conversation %s: %v", conv.Name, err)
+		return false
+	}
+
+	if ok && len(state.Stack) > 0 && state.Stack[0].Conversation == conv.Name {
+		return true
+	}
+
+	return conv.entry != nil && conv.entry(update)
+}
+
+// Handle handles an update in the conversation. It can be registered with
+// Dispatcher.HandleConversation.
+func (conv *Conversation) Handle(ctx context.Context, bot *BotAPI, update Update) error {
+	key, ok := ConversationKeyFor(update)
+	if !ok {
+		return nil
+	}
+
+	state, active, err := conv.store.Load(ctx, key)
+	if err != nil {
+		return err
+	}
+	active = active && len(state.Stack) > 0 && state.Stack[0].Conversation == conv.Name
+
+	if active && conv.Timeout > 0 && time.Now().After(state.Expires) {
+		if err := conv.store.Delete(ctx, key); err != nil {
+			return err
+		}
+		active = false
+
+		if conv.OnTimeout != nil {
+			c := &ConversationContext{Bot: bot, Key: key, Data: state.Data, state: state.Stack[len(state.Stack)-1].State}
+			if err := conv.OnTimeout(ctx, c, update); err != nil {
+				return err
+			}
+		}
+	}
+
+	if active && conv.isCancel(update) {
+		if err := conv.store.Delete(ctx, key); err != nil {
+			return err
+		}
+
+		if conv.OnCancel != nil {
+			c := &ConversationContext{Bot: bot, Key: key, Data: state.Data, state: state.Stack[len(state.Stack)-1].State}
+			return conv.OnCancel(ctx, c, update)
+		}
+
+		return nil
+	}
+
+	var handler StateHandler
+
+	if active {
+		frame := state.Stack[len(state.Stack)-1]
+
+		current := conv.find(frame.Conversation)
+		if current == nil {
+			return fmt.Errorf("unknown conversation %s", frame.Conversation)
+		}
+
+		if handler = current.states[frame.State]; handler == nil {
+			return fmt.Errorf("conversation %s has no state %q", frame.Conversation, frame.State)
+		}
+	} else {
+		if conv.entry == nil || !conv.entry(update) || conv.start == nil {
+			return nil
+		}
+
+		state = ConversationState{
+			Stack: []ConversationFrame{{Conversation: conv.Name}},
+			Data:  make(map[string]string),
+		}
+		handler = conv.start
+	}
+
+	if state.Data == nil {
+		state.Data = make(map[string]string)
+	}
+
+	if err := conv.run(ctx, bot, key, &state, handler, update); err != nil {
+		return err
+	}
+
+	if len(state.Stack) == 0 {
+		return conv.store.Delete(ctx, key)
+	}
+
+	if conv.Timeout > 0 {
+		state.Expires = time.Now().Add(conv.Timeout)
+	}
+
+	return conv.store.Save(ctx, key, state)
+}
+
+// run calls the handler and the handlers following from it, updating the
+// state with their transitions.
+func (conv *Conversation) run(ctx context.Context, bot *BotAPI, key ConversationKey, state *ConversationState, handler StateHandler, update Update) error {
+	for handler != nil {
+		top := &state.Stack[len(state.Stack)-1]
+
+		c := &ConversationContext{Bot: bot, Key: key, Data: state.Data, state: top.State}
+		if err := handler(ctx, c, update); err != nil {
+			return err
+		}
+
+		handler = nil
+
+		if c.nextSet {
+			top.State = c.next
+		}
+
+		switch {
+		case c.sub != nil:
+			if conv.find(c.sub.Name) == nil {
+				return fmt.Errorf("conversation %s was not added with Sub", c.sub.Name)
+			}
+
+			top.State = c.returnState
+			state.Stack = append(state.Stack, ConversationFrame{Conversation: c.sub.Name})
+			handler = c.sub.start
+		case c.ended:
+			state.Stack = state.Stack[:len(state.Stack)-1]
+
+			if len(state.Stack) > 0 {
+				parent := state.Stack[len(state.Stack)-1]
+				handler = conv.find(parent.Conversation).states[parent.State]
+			}
+		}
+	}
+
+	return nil
+}
+
+func (conv *Conversation) isCancel(update Update) bool {
+	return len(conv.CancelCommands) > 0 && IsCommand(conv.CancelCommands...)(update)
+}
+
+// HandleConversation registers a conversation, handling the updates that
+// start it and the updates of users in it.
+//
+// It should be registered before other handlers that could match the
+// updates of users in the conversation.
+func (d *Dispatcher) HandleConversation(conv *Conversation, middleware ...Middleware) {
+	d.Handle(conv.Matches, conv.Handle, middleware...)
+}
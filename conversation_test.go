@@ -0,0 +1,146 @@
+package tgbotapi
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func conversationMessage(userID int64, text string) Update {
+	message := &Message{
+		From: &User{ID: userID},
+		Chat: &Chat{ID: userID, Type: "private"},
+		Text: text,
+	}
+	if strings.HasPrefix(text, "/") {
+		message.Entities = []MessageEntity{{Type: "bot_command", Length: len(text)}}
+	}
+
+	return Update{Message: message}
+}
+
+func newTestConversation(store ConversationStore, log *[]string) *Conversation {
+	phone := NewConversation("phone", store)
+	phone.Entry(nil, func(ctx context.Context, c *ConversationContext, update Update) error {
+		*log = append(*log, "ask phone")
+		c.Next("phone")
+		return nil
+	})
+	phone.State("phone", func(ctx context.Context, c *ConversationContext, update Update) error {
+		c.Data["phone"] = update.Message.Text
+		c.End()
+		return nil
+	})
+
+	conv := NewConversation("register", store)
+	conv.CancelCommands = []string{"cancel"}
+	conv.OnCancel = func(ctx context.Context, c *ConversationContext, update Update) error {
+		*log = append(*log, "cancelled in "+c.State())
+		return nil
+	}
+	conv.Sub(phone)
+	conv.Entry(IsCommand("register"), func(ctx context.Context, c *ConversationContext, update Update) error {
+		*log = append(*log, "ask name")
+		c.Next("name")
+		return nil
+	})
+	conv.State("name", func(ctx context.Context, c *ConversationContext, update Update) error {
+		c.Data["name"] = update.Message.Text
+		c.Begin(phone, "confirm")
+		return nil
+	})
+	conv.State("confirm", func(ctx context.Context, c *ConversationContext, update Update) error {
+		*log = append(*log, "confirm "+c.Data["name"]+" "+c.Data["phone"])
+		c.Next("confirmed")
+		return nil
+	})
+	conv.State("confirmed", func(ctx context.Context, c *ConversationContext, update Update) error {
+		*log = append(*log, "done")
+		c.End()
+		return nil
+	})
+
+	return conv
+}
+
+func TestConversation(t *testing.T) {
+	store := NewMemoryConversationStore()
+
+	var log []string
+	d := NewDispatcher(nil)
+	d.HandleConversation(newTestConversation(store, &log))
+	d.Fallback(func(ctx context.Context, bot *BotAPI, update Update) error {
+		log = append(log, "fallback "+update.Message.Text)
+		return nil
+	})
+
+	for _, update := range []Update{
+		conversationMessage(1, "hello"),
+		conversationMessage(1, "/register"),
+		conversationMessage(2, "hello"),
+		conversationMessage(1, "Ann"),
+		conversationMessage(1, "123"),
+		conversationMessage(1, "yes"),
+		conversationMessage(1, "hello"),
+	} {
+		if err := d.Dispatch(context.Background(), update); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := "fallback hello,ask name,fallback hello,ask phone,confirm Ann 123,done,fallback hello"
+	if got := strings.Join(log, ","); got != want {
+		t.Fatalf("unexpected conversation\n got: %s\nwant: %s", got, want)
+	}
+
+	if _, ok, _ := store.Load(context.Background(), ConversationKey{ChatID: 1, UserID: 1}); ok {
+		t.Fatal("ended conversation was kept")
+	}
+}
+
+func TestConversationCancel(t *testing.T) {
+	store := NewMemoryConversationStore()
+
+	var log []string
+	conv := newTestConversation(store, &log)
+
+	for _, text := range []string{"/register", "Ann", "/cancel", "123"} {
+		if err := conv.Handle(context.Background(), nil, conversationMessage(1, text)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := strings.Join(log, ","); got != "ask name,ask phone,cancelled in phone" {
+		t.Fatalf("unexpected conversation %s", got)
+	}
+}
+
+func TestConversationTimeout(t *testing.T) {
+	store := NewMemoryConversationStore()
+
+	var log []string
+	conv := newTestConversation(store, &log)
+	conv.Timeout = time.Millisecond
+	conv.OnTimeout = func(ctx context.Context, c *ConversationContext, update Update) error {
+		log = append(log, "timed out in "+c.State())
+		return nil
+	}
+
+	if err := conv.Handle(context.Background(), nil, conversationMessage(1, "/register")); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+
+	if err := conv.Handle(context.Background(), nil, conversationMessage(1, "Ann")); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(log, ","); got != "ask name,timed out in name" {
+		t.Fatalf("unexpected conversation %s", got)
+	}
+	if conv.Matches(conversationMessage(1, "Ann")) {
+		t.Fatal("timed out conversation still active")
+	}
+}
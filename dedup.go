@@ -0,0 +1,245 @@
+package tgbotapi
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// UpdateIDStore records the IDs of handled updates so that updates delivered
+// twice, such as webhook requests Telegram retried, are dropped.
+//
+// Updates are only recorded once handled, so updates lost in a crash are
+// accepted when Telegram delivers them again. Updates being handled are
+// tracked by the BotAPI, which records them anyway after 10 minutes or
+// once 10000 more updates arrived.
+//
+// Implementations must be safe for concurrent use.
+type UpdateIDStore interface {
+	// Seen reports whether the update ID was recorded.
+	Seen(ctx context.Context, updateID int) (bool, error)
+	// MarkSeen records the update ID.
+	MarkSeen(ctx context.Context, updateID int) error
+}
+
+// MemoryUpdateIDStore is an UpdateIDStore remembering the most recent
+// update IDs in memory.
+type MemoryUpdateIDStore struct {
+	mu   sync.Mutex
+	seen map[int]bool
+	ids  []int
+	next int
+}
+
+// NewMemoryUpdateIDStore creates a MemoryUpdateIDStore remembering the last
+// size update IDs.
+func NewMemoryUpdateIDStore(size int) *MemoryUpdateIDStore {
+	if size < 1 {
+		size = 1
+	}
+
+	return &MemoryUpdateIDStore{
+		seen: make(map[int]bool, size),
+		ids:  make([]int, 0, size),
+	}
+}
+
+// Seen reports whether the update ID was recorded.
+func (s *MemoryUpdateIDStore) Seen(ctx context.Context, updateID int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.seen[updateID], nil
+}
+
+// MarkSeen records the update ID, forgetting the oldest one if the store is
+// full.
+func (s *MemoryUpdateIDStore) MarkSeen(ctx context.Context, updateID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.seen[updateID] {
+		return nil
+	}
+
+	s.seen[updateID] = true
+
+	if len(s.ids) < cap(s.ids) {
+		s.ids = append(s.ids, updateID)
+		return nil
+	}
+
+	delete(s.seen, s.ids[s.next])
+	s.ids[s.next] = updateID
+	s.next = (s.next + 1) % len(s.ids)
+
+	return nil
+}
+
+// StorageUpdateIDStore is an UpdateIDStore keeping update IDs in a Storage,
+// which lets several instances of a bot share them.
+//
+// An update delivered to two instances at once may be handled by both, as
+// each instance only knows about the updates it is handling itself.
+type StorageUpdateIDStore struct {
+	Storage Storage
+	// Prefix is prepended to the keys of update IDs.
+	Prefix string
+	// TTL is how long update IDs are kept. Telegram gives up delivering an
+	// update after 24 hours.
+	TTL time.Duration
+}
+
+// NewStorageUpdateIDStore creates an UpdateIDStore keeping update IDs in
+// storage for 24 hours under keys starting with "update:".
+func NewStorageUpdateIDStore(storage Storage) *StorageUpdateIDStore {
+	return &StorageUpdateIDStore{Storage: storage, Prefix: "update:", TTL: 24 * time.Hour}
+}
+
+// Seen reports whether the update ID was recorded.
+func (s *StorageUpdateIDStore) Seen(ctx context.Context, updateID int) (bool, error) {
+	_, seen, err := s.Storage.Get(ctx, s.Prefix+strconv.Itoa(updateID))
+
+	return seen, err
+}
+
+// MarkSeen records the update ID.
+func (s *StorageUpdateIDStore) MarkSeen(ctx context.Context, updateID int) error {
+	return s.Storage.Set(ctx, s.Prefix+strconv.Itoa(updateID), []byte{1}, s.TTL)
+}
+
+// pendingUpdateTimeout is how long an update may be handled before it is
+// recorded in the UpdateIDStore as if it was acknowledged, and
+// maxPendingUpdates is how many updates may arrive while it is handled.
+// They keep updates that are never acknowledged from piling up in memory.
+var (
+	pendingUpdateTimeout = 10 * time.Minute
+	maxPendingUpdates    = 10000
+)
+
+// pendingUpdates holds the IDs of the updates being handled.
+type pendingUpdates struct {
+	mu    sync.Mutex
+	ids   map[int]uint64
+	order []pendingUpdate
+	seq   uint64
+}
+
+// pendingUpdate is an update ID in the order updates were added.
+type pendingUpdate struct {
+	id    int
+	seq   uint64
+	added time.Time
+}
+
+// add records the update ID, reporting false if it already was. It returns
+// the IDs of the updates that timed out or were evicted to make room.
+func (p *pendingUpdates) add(updateID int) (bool, []int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+
+	var expired []int
+	for len(p.order) > 0 && (len(p.order) >= maxPendingUpdates || now.Sub(p.order[0].added) >= pendingUpdateTimeout) {
+		oldest := p.order[0]
+		p.order = p.order[1:]
+
+		// Updates handled since they were added are no longer pending.
+		if seq, ok := p.ids[oldest.id]; ok && seq == oldest.seq {
+			delete(p.ids, oldest.id)
+			expired = append(expired, oldest.id)
+		}
+	}
+
+	if _, ok := p.ids[updateID]; ok {
+		return false, expired
+	}
+
+	if p.ids == nil {
+		p.ids = make(map[int]uint64)
+	}
+
+	p.seq++
+	p.ids[updateID] = p.seq
+	p.order = append(p.order, pendingUpdate{id: updateID, seq: p.seq, added: now})
+
+	return true, expired
+}
+
+// remove removes the update ID, reporting whether it was recorded.
+func (p *pendingUpdates) remove(updateID int) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.ids[updateID]; !ok {
+		return false
+	}
+
+	delete(p.ids, updateID)
+
+	return true
+}
+
+// isDuplicate reports whether the update is being handled or was handled
+// before according to bot.UpdateIDStore, calling OnDuplicateUpdate if so.
+// Otherwise the update is recorded as being handled until it is passed to
+// updateHandled or updateDropped, or times out. Updates are accepted if the
+// store fails.
+func (bot *BotAPI) isDuplicate(ctx context.Context, update Update) bool {
+	if bot.UpdateIDStore == nil {
+		return false
+	}
+
+	added, expired := bot.pendingUpdates.add(update.UpdateID)
+	for _, id := range expired {
+		if err := bot.UpdateIDStore.MarkSeen(ctx, id); err != nil {
+			log.Printf("Failed to record update %d as handled: %v", id, err)
+		}
+	}
+
+	seen := !added
+	if !seen {
+		var err error
+		seen, err = bot.UpdateIDStore.Seen(ctx, update.UpdateID)
+		if err != nil {
+			log.Printf("Failed to check update %d for duplicates: %v", update.UpdateID, err)
+			seen = false
+		}
+
+		if seen {
+			bot.pendingUpdates.remove(update.UpdateID)
+		}
+	}
+
+	if seen {
+		if bot.Debug {
+			log.Printf("Dropped duplicate update %d", update.UpdateID)
+		}
+
+		if bot.OnDuplicateUpdate != nil {
+			bot.OnDuplicateUpdate(update)
+		}
+	}
+
+	return seen
+}
+
+// updateHandled records a handled update in bot.UpdateIDStore, so it is
+// dropped if Telegram delivers it again.
+func (bot *BotAPI) updateHandled(ctx context.Context, update Update) error {
+	if bot.UpdateIDStore == nil || !bot.pendingUpdates.remove(update.UpdateID) {
+		return nil
+	}
+
+	return bot.UpdateIDStore.MarkSeen(ctx, update.UpdateID)
+}
+
+// updateDropped forgets an update that couldn't be handled, so it is
+// accepted when Telegram delivers it again.
+func (bot *BotAPI) updateDropped(update Update) {
+	if bot.UpdateIDStore != nil {
+		bot.pendingUpdates.remove(update.UpdateID)
+	}
+}
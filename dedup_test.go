@@ -0,0 +1,218 @@
+package tgbotapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMemoryUpdateIDStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryUpdateIDStore(2)
+
+	if seen, _ := store.Seen(ctx, 1); seen {
+		t.Fatal("update 1 reported as seen")
+	}
+
+	store.MarkSeen(ctx, 1)
+	store.MarkSeen(ctx, 2)
+	if seen, _ := store.Seen(ctx, 1); !seen {
+		t.Fatal("update 1 not reported as seen")
+	}
+
+	// Adding a third update forgets the oldest one.
+	store.MarkSeen(ctx, 3)
+	if seen, _ := store.Seen(ctx, 1); seen {
+		t.Fatal("update 1 still remembered after leaving the window")
+	}
+}
+
+func TestWebhookServerDropsDuplicates(t *testing.T) {
+	var duplicates []int
+
+	bot := &BotAPI{
+		Buffer:        2,
+		UpdateIDStore: NewStorageUpdateIDStore(NewMemoryStorage()),
+		OnDuplicateUpdate: func(update Update) {
+			duplicates = append(duplicates, update.UpdateID)
+		},
+	}
+	server := NewWebhookServer(bot, "")
+
+	for _, body := range []string{`{"update_id":1}`, `{"update_id":1}`, `{"update_id":2}`} {
+		if code := postUpdate(server, "", body); code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", code)
+		}
+	}
+
+	if err := server.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []int
+	for update := range server.Updates() {
+		ids = append(ids, update.UpdateID)
+	}
+
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Fatalf("unexpected updates %v", ids)
+	}
+	if len(duplicates) != 1 || duplicates[0] != 1 {
+		t.Fatalf("unexpected duplicates %v", duplicates)
+	}
+}
+
+func TestWebhookServerForgetsUndeliveredUpdates(t *testing.T) {
+	bot := &BotAPI{UpdateIDStore: NewMemoryUpdateIDStore(10)}
+	server := NewWebhookServer(bot, "")
+
+	// Nobody reads the updates, so the request waits until it is cancelled.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(`{"update_id":1}`)).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rec.Code)
+	}
+	if seen, _ := bot.UpdateIDStore.Seen(context.Background(), 1); seen {
+		t.Fatal("undelivered update was marked as seen")
+	}
+
+	// Not being a duplicate, the update is queued again and fails the same way.
+	req = httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(`{"update_id":1}`)).WithContext(ctx)
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected the update to be accepted again, got %d", rec.Code)
+	}
+}
+
+func TestUpdateIDStoreUnackedUpdateAfterRestart(t *testing.T) {
+	storage := NewMemoryStorage()
+
+	newServer := func() (*BotAPI, *WebhookServer) {
+		bot := &BotAPI{Buffer: 1, UpdateIDStore: NewStorageUpdateIDStore(storage)}
+		return bot, NewWebhookServer(bot, "")
+	}
+
+	// The first instance receives the update and stops before handling it.
+	_, server := newServer()
+	postUpdate(server, "", `{"update_id":1}`)
+	if update := <-server.Updates(); update.UpdateID != 1 {
+		t.Fatalf("expected update 1, got %d", update.UpdateID)
+	}
+
+	// After a restart, the redelivered update is accepted and acknowledged.
+	bot, server := newServer()
+	postUpdate(server, "", `{"update_id":1}`)
+
+	update := <-server.Updates()
+	if update.UpdateID != 1 {
+		t.Fatalf("expected update 1 again, got %d", update.UpdateID)
+	}
+	if err := bot.AckUpdate(update); err != nil {
+		t.Fatal(err)
+	}
+
+	// Once acknowledged, it is a duplicate even after another restart.
+	var duplicates int
+	bot, server = newServer()
+	bot.OnDuplicateUpdate = func(Update) { duplicates++ }
+	postUpdate(server, "", `{"update_id":1}`)
+
+	if duplicates != 1 {
+		t.Fatalf("expected the acknowledged update to be dropped, got %d duplicates", duplicates)
+	}
+}
+
+func TestWebhookServerHandlerRecordsHandledUpdates(t *testing.T) {
+	bot := &BotAPI{UpdateIDStore: NewMemoryUpdateIDStore(10)}
+	server := NewWebhookServer(bot, "")
+
+	var calls int
+	server.Handler = func(ctx context.Context, update Update) error {
+		calls++
+		if calls == 1 {
+			return errors.New("failed")
+		}
+		return nil
+	}
+	server.ErrorHandler = func(Update, error) {}
+
+	for range 3 {
+		postUpdate(server, "", `{"update_id":1}`)
+	}
+
+	// The failed update is handled again, the handled one is dropped.
+	if calls != 2 {
+		t.Fatalf("expected 2 calls, got %d", calls)
+	}
+}
+
+func TestGetUpdatesChanDropsDuplicates(t *testing.T) {
+	bot := newLocalBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if offset := r.FormValue("offset"); offset != "" && offset != "0" {
+			<-r.Context().Done()
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":[{"update_id":1},{"update_id":2}]}`))
+	})
+
+	bot.UpdateIDStore = NewMemoryUpdateIDStore(10)
+	bot.UpdateIDStore.MarkSeen(context.Background(), 1)
+
+	updates := bot.GetUpdatesChan(NewUpdate(0))
+	defer bot.StopReceivingUpdates()
+
+	if update := <-updates; update.UpdateID != 2 {
+		t.Fatalf("expected update 2, got %d", update.UpdateID)
+	}
+}
+
+func TestUpdateIDStoreUnackedUpdatesExpire(t *testing.T) {
+	defer func(timeout time.Duration, size int) {
+		pendingUpdateTimeout, maxPendingUpdates = timeout, size
+	}(pendingUpdateTimeout, maxPendingUpdates)
+
+	ctx := context.Background()
+
+	bot := &BotAPI{Buffer: 10, UpdateIDStore: NewMemoryUpdateIDStore(10)}
+	server := NewWebhookServer(bot, "")
+
+	// Updates read from Updates are never acknowledged. Once 3 more arrived,
+	// the oldest is recorded as handled.
+	maxPendingUpdates = 3
+	for id := 1; id <= 4; id++ {
+		postUpdate(server, "", fmt.Sprintf(`{"update_id":%d}`, id))
+	}
+
+	if seen, _ := bot.UpdateIDStore.Seen(ctx, 1); !seen {
+		t.Fatal("evicted update 1 was not recorded")
+	}
+	if seen, _ := bot.UpdateIDStore.Seen(ctx, 2); seen {
+		t.Fatal("pending update 2 was recorded")
+	}
+
+	// After the timeout, all of them are.
+	maxPendingUpdates = 10
+	pendingUpdateTimeout = time.Millisecond
+	time.Sleep(5 * time.Millisecond)
+	postUpdate(server, "", `{"update_id":5}`)
+
+	for id := 2; id <= 4; id++ {
+		if seen, _ := bot.UpdateIDStore.Seen(ctx, id); !seen {
+			t.Fatalf("timed out update %d was not recorded", id)
+		}
+	}
+	if len(bot.pendingUpdates.ids) != 1 || len(bot.pendingUpdates.order) != 1 {
+		t.Fatalf("expected only update 5 to be pending, got %v", bot.pendingUpdates.ids)
+	}
+}
@@ -0,0 +1,324 @@
+package tgbotapi
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// HandlerFunc handles a single update.
+type HandlerFunc func(ctx context.Context, bot *BotAPI, update Update) error
+
+// Middleware wraps a HandlerFunc to run code before or after it.
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Predicate reports whether a handler should process an update.
+type Predicate func(update Update) bool
+
+type route struct {
+	predicate Predicate
+	handler   HandlerFunc
+}
+
+// Dispatcher routes updates to registered handlers.
+//
+// Handlers are checked in the order they were registered and only the first
+// matching handler is called. If none match, the fallback handler is used.
+type Dispatcher struct {
+	Bot *BotAPI
+
+	// ErrorHandler is called by Run with any error returned from a handler.
+	// If nil, errors are logged.
+	ErrorHandler func(update Update, err error)
+
+	mu         sync.RWMutex
+	middleware []Middleware
+	routes     []route
+	fallback   HandlerFunc
+}
+
+// NewDispatcher creates a new Dispatcher for the bot.
+func NewDispatcher(bot *BotAPI) *Dispatcher {
+	return &Dispatcher{Bot: bot}
+}
+
+// Use appends middleware applied to every handler, including the fallback.
+//
+// Middleware runs in the order it was added, the first being the outermost.
+func (d *Dispatcher) Use(middleware ...Middleware) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.middleware = append(d.middleware, middleware...)
+}
+
+// Handle registers a handler for updates matching the predicate. The
+// middleware only applies to this handler and runs inside the middleware
+// added with Use.
+func (d *Dispatcher) Handle(predicate Predicate, handler HandlerFunc, middleware ...Middleware) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.routes = append(d.routes, route{
+		predicate: predicate,
+		handler:   chain(handler, middleware),
+	})
+}
+
+// HandleUpdateType registers a handler for updates of the given type, such as
+// UpdateTypeMessage or UpdateTypeCallbackQuery.
+func (d *Dispatcher) HandleUpdateType(updateType string, handler HandlerFunc, middleware ...Middleware) {
+	d.Handle(IsUpdateType(updateType), handler, middleware...)
+}
+
+// HandleCommand registers a handler for messages with the command, given
+// without the leading slash. Edited messages are ignored.
+//
+// Commands addressed to another bot with the "/command@bot" syntax are
+// ignored.
+func (d *Dispatcher) HandleCommand(command string, handler HandlerFunc, middleware ...Middleware) {
+	d.Handle(And(IsCommand(command), d.addressedToBot), handler, middleware...)
+}
+
+// HandleCallbackPrefix registers a handler for callback queries with data
+// starting with prefix.
+func (d *Dispatcher) HandleCallbackPrefix(prefix string, handler HandlerFunc, middleware ...Middleware) {
+	d.Handle(HasCallbackPrefix(prefix), handler, middleware...)
+}
+
+// HandleRegexp registers a handler for messages with text or caption matching
+// the regular expression.
+func (d *Dispatcher) HandleRegexp(re *regexp.Regexp, handler HandlerFunc, middleware ...Middleware) {
+	d.Handle(MatchesRegexp(re), handler, middleware...)
+}
+
+// HandleChat registers a handler for updates from chats accepted by is, for
+// example HandleChat(Chat.IsPrivate, handler).
+func (d *Dispatcher) HandleChat(is func(Chat) bool, handler HandlerFunc, middleware ...Middleware) {
+	d.Handle(InChat(is), handler, middleware...)
+}
+
+// Fallback sets the handler called for updates no other handler matched.
+func (d *Dispatcher) Fallback(handler HandlerFunc, middleware ...Middleware) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.fallback = chain(handler, middleware)
+}
+
+// Dispatch sends an update to the first matching handler and returns its
+// error. It returns nil if no handler matched and there is no fallback.
+func (d *Dispatcher) Dispatch(ctx context.Context, update Update) error {
+	d.mu.RLock()
+	handler := d.fallback
+	for _, r := range d.routes {
+		if r.predicate(update) {
+			handler = r.handler
+			break
+		}
+	}
+	middleware := d.middleware
+	d.mu.RUnlock()
+
+	if handler == nil {
+		return nil
+	}
+
+	return chain(handler, middleware)(ctx, d.Bot, update)
+}
+
+// Run dispatches updates from the channel until it is closed or ctx is done.
+// Updates are acknowledged with AckUpdate once handled, even if the handler
+// failed.
+func (d *Dispatcher) Run(ctx context.Context, updates UpdatesChannel) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case update, ok := <-updates:
+			if !ok {
+				return
+			}
+
+			if err := d.Dispatch(ctx, update); err != nil {
+				d.handleError(update, err)
+			}
+
+			if d.Bot != nil {
+				if err := d.Bot.AckUpdateContext(ctx, update); err != nil {
+					log.Printf("Failed to acknowledge update %d: %v", update.UpdateID, err)
+				}
+			}
+		}
+	}
+}
+
+func (d *Dispatcher) handleError(update Update, err error) {
+	if d.ErrorHandler != nil {
+		d.ErrorHandler(update, err)
+		return
+	}
+
+	log.Printf("Failed to handle update %d: %v", update.UpdateID, err)
+}
+
+// addressedToBot rejects commands sent to another bot in group chats.
+func (d *Dispatcher) addressedToBot(update Update) bool {
+	message := commandMessage(update)
+	if message == nil || d.Bot == nil {
+		return true
+	}
+
+	command := message.CommandWithAt()
+	i := strings.Index(command, "@")
+
+	return i == -1 || strings.EqualFold(command[i+1:], d.Bot.Self.UserName)
+}
+
+// chain wraps handler with middleware so that the first middleware is the
+// outermost.
+func chain(handler HandlerFunc, middleware []Middleware) HandlerFunc {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+
+	return handler
+}
+
+// IsUpdateType matches updates of the given type.
+func IsUpdateType(updateType string) Predicate {
+	return func(update Update) bool {
+		return update.UpdateType() == updateType
+	}
+}
+
+// IsCommand matches new messages and channel posts containing any of the
+// commands, given without the leading slash. Editing a message doesn't
+// send its command again, so edited messages are ignored.
+func IsCommand(commands ...string) Predicate {
+	return func(update Update) bool {
+		message := commandMessage(update)
+		if message == nil {
+			return false
+		}
+
+		command := message.Command()
+		if command == "" {
+			return false
+		}
+
+		for _, c := range commands {
+			if c == command {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// commandMessage returns the new message or channel post of the update, or
+// nil if there is none.
+func commandMessage(update Update) *Message {
+	if update.Message != nil {
+		return update.Message
+	}
+
+	return update.ChannelPost
+}
+
+// HasCallbackPrefix matches callback queries with data starting with prefix.
+func HasCallbackPrefix(prefix string) Predicate {
+	return func(update Update) bool {
+		return update.CallbackQuery != nil && strings.HasPrefix(update.CallbackQuery.Data, prefix)
+	}
+}
+
+// MatchesRegexp matches messages with text or caption matching re.
+func MatchesRegexp(re *regexp.Regexp) Predicate {
+	return func(update Update) bool {
+		message := update.EffectiveMessage()
+		if message == nil {
+			return false
+		}
+
+		if message.Text != "" {
+			return re.MatchString(message.Text)
+		}
+
+		return message.Caption != "" && re.MatchString(message.Caption)
+	}
+}
+
+// IsReactionAdded matches reaction updates adding any of the reactions, or
+// adding any reaction if none are given.
+func IsReactionAdded(reactions ...ReactionType) Predicate {
+	return func(update Update) bool {
+		return update.MessageReaction != nil && reactionsMatch(update.MessageReaction.Added(), reactions)
+	}
+}
+
+// IsReactionRemoved matches reaction updates removing any of the reactions,
+// or removing any reaction if none are given.
+func IsReactionRemoved(reactions ...ReactionType) Predicate {
+	return func(update Update) bool {
+		return update.MessageReaction != nil && reactionsMatch(update.MessageReaction.Removed(), reactions)
+	}
+}
+
+func reactionsMatch(changed, reactions []ReactionType) bool {
+	if len(reactions) == 0 {
+		return len(changed) > 0
+	}
+
+	for _, reaction := range changed {
+		if slices.Contains(reactions, reaction) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// InChat matches updates from chats accepted by is.
+func InChat(is func(Chat) bool) Predicate {
+	return func(update Update) bool {
+		chat := update.FromChat()
+		return chat != nil && is(*chat)
+	}
+}
+
+// And matches updates matching all of the predicates.
+func And(predicates ...Predicate) Predicate {
+	return func(update Update) bool {
+		for _, p := range predicates {
+			if !p(update) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// Or matches updates matching any of the predicates.
+func Or(predicates ...Predicate) Predicate {
+	return func(update Update) bool {
+		for _, p := range predicates {
+			if p(update) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// Not matches updates not matching the predicate.
+func Not(predicate Predicate) Predicate {
+	return func(update Update) bool {
+		return !predicate(update)
+	}
+}
@@ -0,0 +1,140 @@
+package tgbotapi
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func commandUpdate(text string) Update {
+	command := strings.SplitN(text, " ", 2)[0]
+
+	return Update{
+		UpdateID: 1,
+		Message: &Message{
+			Text:     text,
+			Chat:     &Chat{ID: 1, Type: "private"},
+			Entities: []MessageEntity{{Type: "bot_command", Offset: 0, Length: len(command)}},
+		},
+	}
+}
+
+func recordingHandler(calls *[]string, name string) HandlerFunc {
+	return func(ctx context.Context, bot *BotAPI, update Update) error {
+		*calls = append(*calls, name)
+		return nil
+	}
+}
+
+func TestDispatcherRouting(t *testing.T) {
+	d := NewDispatcher(&BotAPI{Self: User{UserName: "test_bot"}})
+
+	var calls []string
+	d.HandleCommand("start", recordingHandler(&calls, "start"))
+	d.HandleCallbackPrefix("vote:", recordingHandler(&calls, "vote"))
+	d.HandleRegexp(regexp.MustCompile(`^hello`), recordingHandler(&calls, "hello"))
+	d.HandleChat(Chat.IsGroup, recordingHandler(&calls, "group"))
+	d.HandleUpdateType(UpdateTypeInlineQuery, recordingHandler(&calls, "inline"))
+	d.Fallback(recordingHandler(&calls, "fallback"))
+
+	edited := commandUpdate("/start")
+	edited.EditedMessage, edited.Message = edited.Message, nil
+
+	post := commandUpdate("/start")
+	post.ChannelPost, post.Message = post.Message, nil
+
+	updates := []Update{
+		commandUpdate("/start"),
+		commandUpdate("/start@test_bot"),
+		commandUpdate("/start@other_bot"),
+		edited,
+		post,
+		{CallbackQuery: &CallbackQuery{Data: "vote:1"}},
+		{Message: &Message{Text: "hello there", Chat: &Chat{Type: "private"}}},
+		{Message: &Message{Text: "hi", Chat: &Chat{Type: "group"}}},
+		{InlineQuery: &InlineQuery{}},
+		{CallbackQuery: &CallbackQuery{Data: "other"}},
+	}
+
+	for _, update := range updates {
+		if err := d.Dispatch(context.Background(), update); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := []string{"start", "start", "fallback", "fallback", "start", "vote", "hello", "group", "inline", "fallback"}
+	if strings.Join(calls, ",") != strings.Join(expected, ",") {
+		t.Fatalf("expected %v, got %v", expected, calls)
+	}
+}
+
+func TestDispatcherMiddlewareOrder(t *testing.T) {
+	d := NewDispatcher(nil)
+
+	var calls []string
+	wrap := func(name string) Middleware {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(ctx context.Context, bot *BotAPI, update Update) error {
+				calls = append(calls, name)
+				return next(ctx, bot, update)
+			}
+		}
+	}
+
+	d.Use(wrap("global1"), wrap("global2"))
+	d.Handle(IsUpdateType(UpdateTypeMessage), recordingHandler(&calls, "handler"), wrap("route"))
+
+	if err := d.Dispatch(context.Background(), Update{Message: &Message{}}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "global1,global2,route,handler"
+	if strings.Join(calls, ",") != expected {
+		t.Fatalf("expected %s, got %v", expected, calls)
+	}
+}
+
+func TestDispatcherRunErrors(t *testing.T) {
+	d := NewDispatcher(nil)
+
+	errFailed := errors.New("failed")
+	d.Fallback(func(ctx context.Context, bot *BotAPI, update Update) error {
+		return errFailed
+	})
+
+	var handled []int
+	d.ErrorHandler = func(update Update, err error) {
+		if !errors.Is(err, errFailed) {
+			t.Errorf("unexpected error %v", err)
+		}
+		handled = append(handled, update.UpdateID)
+	}
+
+	ch := make(chan Update, 2)
+	ch <- Update{UpdateID: 1}
+	ch <- Update{UpdateID: 2}
+	close(ch)
+
+	d.Run(context.Background(), ch)
+
+	if len(handled) != 2 {
+		t.Fatalf("expected 2 errors, got %d", len(handled))
+	}
+}
+
+func TestPredicates(t *testing.T) {
+	update := Update{CallbackQuery: &CallbackQuery{Data: "a:b"}}
+
+	if !And(HasCallbackPrefix("a:"), IsUpdateType(UpdateTypeCallbackQuery))(update) {
+		t.Error("And should match")
+	}
+	if Or(IsCommand("start"), Not(HasCallbackPrefix("a")))(update) {
+		t.Error("Or should not match")
+	}
+	// Inline callback queries have no message and therefore no chat.
+	if InChat(Chat.IsPrivate)(update) {
+		t.Error("InChat should not match without a chat")
+	}
+}
@@ -0,0 +1,131 @@
+package tgbotapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MaxDownloadFileSize is the largest file the Bot API lets bots download.
+// Bot API servers running in local mode don't have this limit, see
+// SetLocalServer.
+const MaxDownloadFileSize = 20 << 20
+
+// DownloadFile gets the file with getFile and opens it for reading. The
+// returned File describes it, and the reader must be closed.
+//
+// Files larger than MaxDownloadFileSize fail with an error matching
+// ErrFileTooBig before anything is downloaded. When a self-hosted Bot API
+// server returns an absolute path, the file is opened from the local disk.
+func (bot *BotAPI) DownloadFile(ctx context.Context, fileID string) (io.ReadCloser, File, error) {
+	file, err := bot.GetFileContext(ctx, FileConfig{FileID: fileID})
+	if err != nil {
+		return nil, File{}, err
+	}
+
+	if filepath.IsAbs(file.FilePath) {
+		f, err := os.Open(file.FilePath)
+		if err != nil {
+			return nil, file, err
+		}
+
+		return f, file, nil
+	}
+
+	if file.FileSize > MaxDownloadFileSize && !bot.localServer {
+		return nil, file, fmt.Errorf("%w: %d bytes is over the download limit", ErrFileTooBig, file.FileSize)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, bot.fileURL(file.FilePath), nil)
+	if err != nil {
+		return nil, file, bot.redactToken(err)
+	}
+
+	resp, err := bot.Client.Do(req)
+	if err != nil {
+		return nil, file, bot.redactToken(err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+
+		return nil, file, &Error{
+			Code:    resp.StatusCode,
+			Message: fmt.Sprintf("download of %s failed: %s", file.FilePath, resp.Status),
+		}
+	}
+
+	return resp.Body, file, nil
+}
+
+// DownloadFileTo downloads the file to path. The file is written to a
+// temporary file next to path first, so path is either left untouched or
+// contains the whole file.
+func (bot *BotAPI) DownloadFileTo(ctx context.Context, fileID, path string) (File, error) {
+	r, file, err := bot.DownloadFile(ctx, fileID)
+	if err != nil {
+		return file, err
+	}
+	defer r.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return file, err
+	}
+
+	if err := writeAndClose(tmp, r); err != nil {
+		os.Remove(tmp.Name())
+		return file, bot.redactToken(err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return file, err
+	}
+
+	return file, nil
+}
+
+func writeAndClose(f *os.File, r io.Reader) error {
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// fileURL returns the URL to download the file at filePath from.
+func (bot *BotAPI) fileURL(filePath string) string {
+	endpoint := bot.fileEndpoint
+	if endpoint == "" {
+		endpoint = FileEndpoint
+		if base, ok := strings.CutSuffix(bot.apiEndpoint, "/bot%s/%s"); ok {
+			endpoint = base + "/file/bot%s/%s"
+		}
+	}
+
+	return fmt.Sprintf(endpoint, bot.Token, filePath)
+}
+
+// redactToken removes the token from the URL in errors from the HTTP client
+// so that it doesn't end up in logs.
+func (bot *BotAPI) redactToken(err error) error {
+	var urlErr *url.Error
+	if bot.Token != "" && errors.As(err, &urlErr) {
+		urlErr.URL = strings.ReplaceAll(urlErr.URL, bot.Token, "<token>")
+	}
+
+	return err
+}
@@ -0,0 +1,107 @@
+package tgbotapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newFileBot(t *testing.T, filePath string, fileSize int) (*BotAPI, *int) {
+	downloads := 0
+
+	bot := newLocalBot(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/getFile"):
+			fmt.Fprintf(w, `{"ok":true,"result":{"file_id":"id","file_unique_id":"uid","file_path":%q,"file_size":%d}}`, filePath, fileSize)
+		case r.URL.Path == "/file/bottoken/docs/a.txt":
+			downloads++
+			_, _ = w.Write([]byte("abc"))
+		default:
+			downloads++
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	return bot, &downloads
+}
+
+func TestDownloadFile(t *testing.T) {
+	bot, _ := newFileBot(t, "docs/a.txt", 3)
+
+	r, file, err := bot.DownloadFile(context.Background(), "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "abc" || file.FileSize != 3 {
+		t.Fatalf("unexpected download %q of %+v", data, file)
+	}
+
+	path := filepath.Join(t.TempDir(), "a.txt")
+	if _, err := bot.DownloadFileTo(context.Background(), "id", path); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err = os.ReadFile(path)
+	if err != nil || string(data) != "abc" {
+		t.Fatalf("unexpected file %q: %v", data, err)
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Fatalf("temporary files left behind: %v", entries)
+	}
+}
+
+func TestDownloadFileErrors(t *testing.T) {
+	bot, downloads := newFileBot(t, "docs/big.bin", MaxDownloadFileSize+1)
+
+	_, _, err := bot.DownloadFile(context.Background(), "id")
+	if !errors.Is(err, ErrFileTooBig) {
+		t.Fatalf("expected ErrFileTooBig, got %v", err)
+	}
+	if *downloads != 0 {
+		t.Fatal("file was downloaded")
+	}
+
+	bot, _ = newFileBot(t, "docs/missing.txt", 3)
+
+	path := filepath.Join(t.TempDir(), "missing.txt")
+	if _, err := bot.DownloadFileTo(context.Background(), "id", path); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatal("file was created")
+	}
+}
+
+func TestDownloadFileLocalPath(t *testing.T) {
+	local := filepath.Join(t.TempDir(), "local.txt")
+	if err := os.WriteFile(local, []byte("local"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	bot, downloads := newFileBot(t, local, MaxDownloadFileSize+1)
+
+	r, _, err := bot.DownloadFile(context.Background(), "id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	data, _ := io.ReadAll(r)
+	if string(data) != "local" || *downloads != 0 {
+		t.Fatalf("unexpected download %q", data)
+	}
+}
@@ -0,0 +1,149 @@
+package tgbotapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Errors an *Error returned by the Telegram API can be matched against with
+// errors.Is. They are classified from the error code and description.
+//
+// Specific errors also match the general error for their code, so a
+// ErrMessageNotModified error is also an ErrBadRequest.
+var (
+	// ErrBadRequest matches any error with code 400.
+	ErrBadRequest = errors.New("bad request")
+	// ErrUnauthorized matches errors caused by an invalid bot token.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden matches any error with code 403.
+	ErrForbidden = errors.New("forbidden")
+	// ErrNotFound matches any error with code 404.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict matches errors caused by another getUpdates request or an
+	// active webhook.
+	ErrConflict = errors.New("conflict")
+	// ErrTooManyRequests matches flood control errors. The time to wait is in
+	// Error.RetryAfter.
+	ErrTooManyRequests = errors.New("too many requests")
+	// ErrServerError matches errors with a 5xx code.
+	ErrServerError = errors.New("server error")
+
+	// ErrBotBlocked matches errors sending to a user who blocked the bot.
+	ErrBotBlocked = errors.New("bot was blocked by the user")
+	// ErrBotKicked matches errors sending to a group or channel the bot was
+	// removed from.
+	ErrBotKicked = errors.New("bot was kicked from the chat")
+	// ErrUserDeactivated matches errors sending to a deleted account.
+	ErrUserDeactivated = errors.New("user is deactivated")
+	// ErrChatNotFound matches errors for unknown chats.
+	ErrChatNotFound = errors.New("chat not found")
+	// ErrUserNotFound matches errors for unknown users.
+	ErrUserNotFound = errors.New("user not found")
+	// ErrChatMigrated matches errors for groups that were upgraded to a
+	// supergroup. The new chat ID is in Error.MigrateToChatID.
+	ErrChatMigrated = errors.New("group chat was upgraded to a supergroup chat")
+	// ErrMessageNotModified matches edits that don't change the message.
+	ErrMessageNotModified = errors.New("message is not modified")
+	// ErrMessageToEditNotFound matches edits of messages that don't exist.
+	ErrMessageToEditNotFound = errors.New("message to edit not found")
+	// ErrMessageToDeleteNotFound matches deletions of messages that don't
+	// exist.
+	ErrMessageToDeleteNotFound = errors.New("message to delete not found")
+	// ErrMessageCantBeEdited matches edits of messages the bot can't edit.
+	ErrMessageCantBeEdited = errors.New("message can't be edited")
+	// ErrMessageCantBeDeleted matches deletions of messages the bot can't
+	// delete.
+	ErrMessageCantBeDeleted = errors.New("message can't be deleted")
+	// ErrQueryTooOld matches answers to callback or inline queries that
+	// expired.
+	ErrQueryTooOld = errors.New("query is too old")
+	// ErrFileTooBig matches files that exceed the size limits.
+	ErrFileTooBig = errors.New("file is too big")
+	// ErrInvalidFileID matches requests with an unknown file_id.
+	ErrInvalidFileID = errors.New("wrong file identifier")
+)
+
+// errorDescriptions maps parts of error descriptions to the specific errors
+// they indicate.
+var errorDescriptions = []struct {
+	text string
+	err  error
+}{
+	{"bot was blocked by the user", ErrBotBlocked},
+	{"bot was kicked from", ErrBotKicked},
+	{"bot is not a member of", ErrBotKicked},
+	{"user is deactivated", ErrUserDeactivated},
+	{"chat not found", ErrChatNotFound},
+	{"user not found", ErrUserNotFound},
+	{"group chat was upgraded to a supergroup chat", ErrChatMigrated},
+	{"message is not modified", ErrMessageNotModified},
+	{"message to edit not found", ErrMessageToEditNotFound},
+	{"message to delete not found", ErrMessageToDeleteNotFound},
+	{"message can't be edited", ErrMessageCantBeEdited},
+	{"message can't be deleted", ErrMessageCantBeDeleted},
+	{"query is too old", ErrQueryTooOld},
+	{"file is too big", ErrFileTooBig},
+	{"wrong file identifier", ErrInvalidFileID},
+	{"wrong remote file identifier", ErrInvalidFileID},
+}
+
+// Kind returns the most specific of the errors above that e matches, or nil
+// if it matches none of them.
+func (e Error) Kind() error {
+	switch {
+	case e.MigrateToChatID != 0:
+		return ErrChatMigrated
+	case e.RetryAfter > 0:
+		return ErrTooManyRequests
+	}
+
+	description := strings.ToLower(e.Message)
+	for _, d := range errorDescriptions {
+		if strings.Contains(description, d.text) {
+			return d.err
+		}
+	}
+
+	if e.Code == http.StatusRequestEntityTooLarge {
+		return ErrFileTooBig
+	}
+
+	return e.codeKind()
+}
+
+// codeKind returns the general error for the code of e.
+func (e Error) codeKind() error {
+	switch {
+	case e.Code == http.StatusBadRequest:
+		return ErrBadRequest
+	case e.Code == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case e.Code == http.StatusForbidden:
+		return ErrForbidden
+	case e.Code == http.StatusNotFound:
+		return ErrNotFound
+	case e.Code == http.StatusConflict:
+		return ErrConflict
+	case e.Code == http.StatusTooManyRequests:
+		return ErrTooManyRequests
+	case e.Code >= http.StatusInternalServerError:
+		return ErrServerError
+	default:
+		return nil
+	}
+}
+
+// Is reports whether e matches target, one of the errors above, so that
+// errors.Is can be used with errors returned by the API.
+func (e Error) Is(target error) bool {
+	if target == nil {
+		return false
+	}
+
+	if kind := e.Kind(); kind == target {
+		return true
+	}
+
+	return e.codeKind() == target
+}
@@ -0,0 +1,70 @@
+package tgbotapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestErrorIs(t *testing.T) {
+	tests := []struct {
+		err     Error
+		kind    error
+		general error
+	}{
+		{Error{Code: 403, Message: "Forbidden: bot was blocked by the user"}, ErrBotBlocked, ErrForbidden},
+		{Error{Code: 403, Message: "Forbidden: bot was kicked from the supergroup chat"}, ErrBotKicked, ErrForbidden},
+		{Error{Code: 403, Message: "Forbidden: user is deactivated"}, ErrUserDeactivated, ErrForbidden},
+		{Error{Code: 400, Message: "Bad Request: chat not found"}, ErrChatNotFound, ErrBadRequest},
+		{Error{Code: 400, Message: "Bad Request: message is not modified: specified new message content and reply markup are exactly the same"}, ErrMessageNotModified, ErrBadRequest},
+		{Error{Code: 400, Message: "Bad Request: message to edit not found"}, ErrMessageToEditNotFound, ErrBadRequest},
+		{Error{Code: 400, Message: "Bad Request: group chat was upgraded to a supergroup chat", ResponseParameters: ResponseParameters{MigrateToChatID: -100123}}, ErrChatMigrated, ErrBadRequest},
+		{Error{Code: 400, Message: "Bad Request: file is too big"}, ErrFileTooBig, ErrBadRequest},
+		{Error{Code: 413, Message: "Request Entity Too Large"}, ErrFileTooBig, nil},
+		{Error{Code: 429, Message: "Too Many Requests: retry after 5", ResponseParameters: ResponseParameters{RetryAfter: 5}}, ErrTooManyRequests, ErrTooManyRequests},
+		{Error{Code: 401, Message: "Unauthorized"}, ErrUnauthorized, ErrUnauthorized},
+		{Error{Code: 409, Message: "Conflict: terminated by other getUpdates request"}, ErrConflict, ErrConflict},
+		{Error{Code: 502, Message: "502 Bad Gateway"}, ErrServerError, ErrServerError},
+		{Error{Code: 400, Message: "Bad Request: can't parse entities"}, ErrBadRequest, ErrBadRequest},
+	}
+
+	for _, test := range tests {
+		err := fmt.Errorf("wrapped: %w", &test.err)
+
+		if kind := test.err.Kind(); kind != test.kind {
+			t.Errorf("%q: expected kind %v, got %v", test.err.Message, test.kind, kind)
+		}
+		if !errors.Is(err, test.kind) {
+			t.Errorf("%q: expected to match %v", test.err.Message, test.kind)
+		}
+		if test.general != nil && !errors.Is(err, test.general) {
+			t.Errorf("%q: expected to match %v", test.err.Message, test.general)
+		}
+		if errors.Is(err, ErrNotFound) {
+			t.Errorf("%q: unexpectedly matched %v", test.err.Message, ErrNotFound)
+		}
+
+		var apiErr *Error
+		if !errors.As(err, &apiErr) || apiErr.Code != test.err.Code {
+			t.Errorf("%q: expected errors.As to find the API error", test.err.Message)
+		}
+	}
+}
+
+func TestUploadFilesErrorCode(t *testing.T) {
+	bot := newLocalBot(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"ok":false,"error_code":403,"description":"Forbidden: bot was blocked by the user"}`))
+	})
+
+	_, err := bot.Send(NewDocument(int64(1), FileBytes{Name: "doc.txt", Bytes: []byte("doc")}))
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Code != http.StatusForbidden {
+		t.Fatalf("expected error with code 403, got %#v", err)
+	}
+	if !errors.Is(err, ErrBotBlocked) {
+		t.Fatalf("expected ErrBotBlocked, got %v", err)
+	}
+}
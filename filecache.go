@@ -0,0 +1,235 @@
+package tgbotapi
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// FileIDCache stores the file IDs Telegram assigned to uploaded files.
+//
+// Keys identify a file by the method it was sent with and its content or
+// path, and are computed by the BotAPI. Implementations must be safe for
+// concurrent use.
+type FileIDCache interface {
+	Get(key string) (fileID string, ok bool)
+	Set(key, fileID string)
+	Delete(key string)
+}
+
+// LRUFileIDCache is an in-memory FileIDCache forgetting the least recently
+// used file IDs once it is full.
+type LRUFileIDCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List
+}
+
+type lruEntry struct {
+	key    string
+	fileID string
+}
+
+// NewLRUFileIDCache creates an LRUFileIDCache holding up to capacity file
+// IDs.
+func NewLRUFileIDCache(capacity int) *LRUFileIDCache {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	return &LRUFileIDCache{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Get returns the file ID stored for key.
+func (c *LRUFileIDCache) Get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+
+	c.order.MoveToFront(e)
+
+	return e.Value.(*lruEntry).fileID, true
+}
+
+// Set stores the file ID for key.
+func (c *LRUFileIDCache) Set(key, fileID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*lruEntry).fileID = fileID
+		c.order.MoveToFront(e)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, fileID: fileID})
+
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// Delete forgets the file ID stored for key.
+func (c *LRUFileIDCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		c.order.Remove(e)
+		delete(c.entries, key)
+	}
+}
+
+// Len returns the number of file IDs stored.
+func (c *LRUFileIDCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// cachedFileFields maps the methods whose sent Message holds the ID of the
+// uploaded file to the parameter of that file. Other methods, such as
+// setChatPhoto, only accept uploads. Thumbnails can't be reused and files in
+// media groups are not cached.
+var cachedFileFields = map[string]string{
+	"sendPhoto":     "photo",
+	"sendDocument":  "document",
+	"sendVideo":     "video",
+	"sendAudio":     "audio",
+	"sendVoice":     "voice",
+	"sendSticker":   "sticker",
+	"sendAnimation": "animation",
+	"sendVideoNote": "video_note",
+}
+
+// requestWithFileIDCache makes a request with files, replacing those with a
+// cached file ID and caching the IDs of the ones uploaded.
+//
+// If Telegram no longer accepts a cached file ID, it is forgotten and the
+// file uploaded again.
+func (bot *BotAPI) requestWithFileIDCache(ctx context.Context, method string, params Params, files []RequestFile) (*APIResponse, error) {
+	keys := make(map[string]string)
+	cached := make([]RequestFile, len(files))
+	usedCache := false
+
+	for i, file := range files {
+		cached[i] = file
+
+		key, ok := fileIDCacheKey(method, file)
+		if !ok {
+			continue
+		}
+		keys[file.Name] = key
+
+		if fileID, ok := bot.FileIDCache.Get(key); ok {
+			cached[i].Data = FileID(fileID)
+			usedCache = true
+		}
+	}
+
+	if len(keys) == 0 {
+		return bot.requestFiles(ctx, method, params, files)
+	}
+
+	original := make(Params, len(params))
+	for key, value := range params {
+		original[key] = value
+	}
+
+	resp, err := bot.requestFiles(ctx, method, params, cached)
+	if err != nil && usedCache && errors.Is(err, ErrInvalidFileID) {
+		for _, key := range keys {
+			bot.FileIDCache.Delete(key)
+		}
+
+		resp, err = bot.requestFiles(ctx, method, original, files)
+	}
+
+	if err != nil {
+		return resp, err
+	}
+
+	var message Message
+	if json.Unmarshal(resp.Result, &message) == nil {
+		for name, key := range keys {
+			if fileID := messageFileID(message, name); fileID != "" {
+				bot.FileIDCache.Set(key, fileID)
+			}
+		}
+	}
+
+	return resp, nil
+}
+
+// fileIDCacheKey returns the key a file sent with method is cached by.
+// Files are identified by the hash of their content, or by their path, size
+// and modification time.
+func fileIDCacheKey(method string, file RequestFile) (string, bool) {
+	if cachedFileFields[method] != file.Name {
+		return "", false
+	}
+
+	switch data := file.Data.(type) {
+	case FileBytes:
+		sum := sha256.Sum256(data.Bytes)
+		return method + ":sha256:" + hex.EncodeToString(sum[:]), true
+	case FilePath:
+		abs, err := filepath.Abs(string(data))
+		if err != nil {
+			return "", false
+		}
+
+		info, err := os.Stat(abs)
+		if err != nil {
+			return "", false
+		}
+
+		return method + ":path:" + abs + ":" + strconv.FormatInt(info.Size(), 10) +
+			":" + strconv.FormatInt(info.ModTime().UnixNano(), 10), true
+	default:
+		return "", false
+	}
+}
+
+// messageFileID returns the ID of the file sent as the parameter.
+func messageFileID(message Message, field string) string {
+	switch {
+	case field == "photo" && len(message.Photo) > 0:
+		return message.Photo[len(message.Photo)-1].FileID
+	case field == "document" && message.Document != nil:
+		return message.Document.FileID
+	case field == "video" && message.Video != nil:
+		return message.Video.FileID
+	case field == "audio" && message.Audio != nil:
+		return message.Audio.FileID
+	case field == "voice" && message.Voice != nil:
+		return message.Voice.FileID
+	case field == "sticker" && message.Sticker != nil:
+		return message.Sticker.FileID
+	case field == "animation" && message.Animation != nil:
+		return message.Animation.FileID
+	case field == "video_note" && message.VideoNote != nil:
+		return message.VideoNote.FileID
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,109 @@
+package tgbotapi
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestLRUFileIDCache(t *testing.T) {
+	cache := NewLRUFileIDCache(2)
+
+	cache.Set("a", "1")
+	cache.Set("b", "2")
+	cache.Get("a")
+	cache.Set("c", "3")
+
+	if _, ok := cache.Get("b"); ok {
+		t.Fatal("least recently used entry was kept")
+	}
+	if id, ok := cache.Get("a"); !ok || id != "1" {
+		t.Fatal("recently used entry was evicted")
+	}
+
+	cache.Delete("a")
+	if _, ok := cache.Get("a"); ok || cache.Len() != 1 {
+		t.Fatal("entry was not deleted")
+	}
+}
+
+func TestFileIDCache(t *testing.T) {
+	var sent []string
+
+	bot := newLocalBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			sent = append(sent, "upload")
+		} else {
+			_ = r.ParseForm()
+			document := r.PostForm.Get("document")
+			sent = append(sent, document)
+
+			if document == "stale" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: wrong file identifier/HTTP URL specified"}`))
+				return
+			}
+		}
+
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":1,"date":0,"chat":{"id":1,"type":"private"},"document":{"file_id":"doc-id","file_unique_id":"u"}}}`))
+	})
+	bot.FileIDCache = NewLRUFileIDCache(10)
+
+	file := FileBytes{Name: "a.txt", Bytes: []byte("content")}
+
+	for i := 0; i < 2; i++ {
+		if _, err := bot.Send(NewDocument(int64(1), file)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if strings.Join(sent, ",") != "upload,doc-id" {
+		t.Fatalf("unexpected requests %v", sent)
+	}
+
+	key, _ := fileIDCacheKey("sendDocument", RequestFile{Name: "document", Data: file})
+	bot.FileIDCache.Set(key, "stale")
+	sent = nil
+
+	if _, err := bot.Send(NewDocument(int64(1), file)); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(sent, ",") != "stale,upload" {
+		t.Fatalf("unexpected requests %v", sent)
+	}
+	if id, _ := bot.FileIDCache.Get(key); id != "doc-id" {
+		t.Fatalf("cache was not updated, got %q", id)
+	}
+}
+
+func TestFileIDCacheOnlySendMethods(t *testing.T) {
+	var requests []string
+
+	bot := newLocalBot(t, func(w http.ResponseWriter, r *http.Request) {
+		method := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			t.Errorf("%s was sent without uploading the file", method)
+		}
+		requests = append(requests, method)
+
+		if method == "setChatPhoto" {
+			_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":1,"date":0,"chat":{"id":1,"type":"private"},"photo":[{"file_id":"photo-id","file_unique_id":"u"}]}}`))
+	})
+	bot.FileIDCache = NewLRUFileIDCache(10)
+
+	file := FileBytes{Name: "photo.jpg", Bytes: []byte("photo")}
+
+	if _, err := bot.Send(NewPhoto(int64(1), file)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bot.Request(SetChatPhotoConfig{BaseFile{BaseChat: BaseChat{ChatID: 1}, File: file}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(requests, ",") != "sendPhoto,setChatPhoto" {
+		t.Fatalf("unexpected requests %v", requests)
+	}
+}
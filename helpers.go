@@ -35,6 +35,58 @@ func NewDeleteMessage(chatID int64, messageID int) DeleteMessageConfig {
 	}
 }
 
+// NewSetMessageReaction creates a new setMessageReaction request replacing
+// the reactions of the bot to a message.
+// Now chatID can be int64, BaseChat, ChatConfig, ChatActionConfig, Chat, User
+func NewSetMessageReaction(chatID any, messageID int, reaction ...ReactionType) SetMessageReactionConfig {
+	return SetMessageReactionConfig{
+		ChatID:    getChatID(chatID),
+		MessageID: messageID,
+		Reaction:  reaction,
+	}
+}
+
+// NewReactionEmoji creates an emoji reaction.
+func NewReactionEmoji(emoji string) ReactionType {
+	return ReactionType{Type: ReactionTypeEmoji, Emoji: emoji}
+}
+
+// NewReactionCustomEmoji creates a custom emoji reaction.
+func NewReactionCustomEmoji(customEmojiID string) ReactionType {
+	return ReactionType{Type: ReactionTypeCustomEmoji, CustomEmojiID: customEmojiID}
+}
+
+// NewReactionPaid creates a paid reaction.
+func NewReactionPaid() ReactionType {
+	return ReactionType{Type: ReactionTypePaid}
+}
+
+// NewDeleteMessages creates a request to delete several messages.
+func NewDeleteMessages(chatID int64, messageIDs ...int) DeleteMessagesConfig {
+	return DeleteMessagesConfig{
+		ChatID:     chatID,
+		MessageIDs: messageIDs,
+	}
+}
+
+// NewForwardMessages creates a request to forward several messages.
+func NewForwardMessages(chatID, fromChatID int64, messageIDs ...int) ForwardMessagesConfig {
+	return ForwardMessagesConfig{
+		ChatID:     chatID,
+		FromChatID: fromChatID,
+		MessageIDs: messageIDs,
+	}
+}
+
+// NewCopyMessages creates a request to copy several messages.
+func NewCopyMessages(chatID, fromChatID int64, messageIDs ...int) CopyMessagesConfig {
+	return CopyMessagesConfig{
+		ChatID:     chatID,
+		FromChatID: fromChatID,
+		MessageIDs: messageIDs,
+	}
+}
+
 // NewMessageToChannel creates a new Message that is sent to a channel
 // by username.
 //
@@ -143,6 +195,16 @@ func NewSticker(chatID any, file RequestFileData) StickerConfig {
 	}
 }
 
+// NewInputSticker creates a sticker to add to a sticker set with the emoji
+// associated with it.
+func NewInputSticker(sticker RequestFileData, format StickerFormat, emoji ...string) InputSticker {
+	return InputSticker{
+		Sticker:   sticker,
+		Format:    format,
+		EmojiList: emoji,
+	}
+}
+
 // NewVideo creates a new sendVideo request.
 // Now chatID can be int64, BaseChat, ChatConfig, ChatActionConfig, Chat, User
 func NewVideo(chatID any, file RequestFileData) VideoConfig {
@@ -197,9 +259,10 @@ func NewVoice(chatID any, file RequestFileData) VoiceConfig {
 }
 
 // NewMediaGroup creates a new media group. Files should be an array of
-// two to ten InputMediaPhoto or InputMediaVideo.
+// two to ten InputMediaPhoto and InputMediaVideo, InputMediaAudio or
+// InputMediaDocument.
 // Now chatID can be int64, BaseChat, ChatConfig, ChatActionConfig, Chat, User
-func NewMediaGroup(chatID any, files []interface{}) MediaGroupConfig {
+func NewMediaGroup(chatID any, files []InputMedia) MediaGroupConfig {
 	toID := getChatID(chatID)
 	return MediaGroupConfig{
 		ChatID: toID,
@@ -0,0 +1,159 @@
+package tgbotapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// NewBotAPIWithLocalServer creates a new BotAPI instance using a self-hosted
+// Bot API server running in local mode at serverURL, such as
+// "http://localhost:8081".
+//
+// The bot must have been logged out of the cloud server first, see
+// MigrateToLocalServer.
+func NewBotAPIWithLocalServer(token, serverURL string) (*BotAPI, error) {
+	bot, err := NewBotAPIWithClient(token, localEndpoint(serverURL), &http.Client{})
+	if err != nil {
+		return nil, err
+	}
+
+	bot.SetLocalServer(serverURL)
+
+	return bot, nil
+}
+
+// SetLocalServer makes the bot use a self-hosted Bot API server running in
+// local mode at serverURL for requests and downloads.
+//
+// Files given as FilePath are then passed to the server by their path
+// instead of being uploaded, which requires the server to have access to
+// them, and files larger than MaxDownloadFileSize can be downloaded.
+func (bot *BotAPI) SetLocalServer(serverURL string) {
+	serverURL = strings.TrimSuffix(serverURL, "/")
+
+	bot.apiEndpoint = localEndpoint(serverURL)
+	bot.fileEndpoint = serverURL + "/file/bot%s/%s"
+	bot.localServer = true
+}
+
+// IsLocalServer reports whether the bot uses a Bot API server in local mode.
+func (bot *BotAPI) IsLocalServer() bool {
+	return bot.localServer
+}
+
+// MigrateToLocalServer moves the bot to a self-hosted Bot API server running
+// in local mode at serverURL.
+//
+// The bot is logged out of the cloud server, or closed on its current local
+// server after deleting the webhook, and then uses the new server. The cloud
+// server can't be used again for 10 minutes after logging out. Updates
+// should not be received while migrating.
+func (bot *BotAPI) MigrateToLocalServer(ctx context.Context, serverURL string) error {
+	if err := bot.leaveServer(ctx); err != nil {
+		return err
+	}
+
+	bot.SetLocalServer(serverURL)
+
+	return bot.checkServer(ctx)
+}
+
+// MigrateToCloud moves the bot from a local Bot API server back to the cloud
+// server. The webhook is deleted and the bot closed on the local server.
+//
+// The cloud server refuses bots that logged out less than 10 minutes ago,
+// and the local server refuses to close bots launched less than 10 minutes
+// ago. An error is returned without making any request if the bot doesn't
+// use a local server.
+func (bot *BotAPI) MigrateToCloud(ctx context.Context) error {
+	if !bot.localServer {
+		return errors.New("bot is not using a local Bot API server")
+	}
+
+	if err := bot.leaveServer(ctx); err != nil {
+		return err
+	}
+
+	bot.apiEndpoint = APIEndpoint
+	bot.fileEndpoint = FileEndpoint
+	bot.localServer = false
+
+	return bot.checkServer(ctx)
+}
+
+// leaveServer stops the bot from using the current server.
+func (bot *BotAPI) leaveServer(ctx context.Context) error {
+	if !bot.localServer {
+		_, err := bot.RequestContext(ctx, LogOutConfig{})
+		return err
+	}
+
+	// The webhook must be deleted so the bot isn't started again when the
+	// local server restarts.
+	if _, err := bot.RequestContext(ctx, DeleteWebhookConfig{}); err != nil {
+		return err
+	}
+
+	_, err := bot.RequestContext(ctx, CloseConfig{})
+
+	return err
+}
+
+// checkServer makes sure the new server accepts the bot.
+func (bot *BotAPI) checkServer(ctx context.Context) error {
+	self, err := bot.GetMeContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	bot.Self = self
+
+	return nil
+}
+
+func localEndpoint(serverURL string) string {
+	return strings.TrimSuffix(serverURL, "/") + "/bot%s/%s"
+}
+
+// localFiles replaces files given by path with file:// URLs a local server
+// reads directly, including in attach:// references within params. Files
+// only referenced that way are removed from the returned files.
+func localFiles(files []RequestFile, params Params) ([]RequestFile, error) {
+	local := make([]RequestFile, 0, len(files))
+
+	for _, file := range files {
+		path, ok := file.Data.(FilePath)
+		if !ok {
+			local = append(local, file)
+			continue
+		}
+
+		abs, err := filepath.Abs(string(path))
+		if err != nil {
+			return nil, err
+		}
+
+		fileURL := "file://" + filepath.ToSlash(abs)
+
+		attach, _ := json.Marshal("attach://" + file.Name)
+		replacement, _ := json.Marshal(fileURL)
+
+		attached := false
+		for key, value := range params {
+			if strings.Contains(value, string(attach)) {
+				params[key] = strings.ReplaceAll(value, string(attach), string(replacement))
+				attached = true
+			}
+		}
+
+		if !attached {
+			local = append(local, RequestFile{Name: file.Name, Data: FileURL(fileURL)})
+		}
+	}
+
+	return local, nil
+}
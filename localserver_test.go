@@ -0,0 +1,140 @@
+package tgbotapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingServer answers every method successfully and records the
+// methods called and their parameters.
+type recordingServer struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	calls  []string
+	params []map[string]string
+}
+
+func newRecordingServer(t *testing.T) *recordingServer {
+	s := &recordingServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			t.Errorf("unexpected multipart request to %s", r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+
+		params := make(map[string]string)
+		for key := range r.PostForm {
+			params[key] = r.PostForm.Get(key)
+		}
+
+		s.mu.Lock()
+		s.calls = append(s.calls, r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:])
+		s.params = append(s.params, params)
+		s.mu.Unlock()
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/getMe"):
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"id":1,"is_bot":true,"first_name":"test","username":"test_bot"}}`))
+		case strings.HasSuffix(r.URL.Path, "/sendMediaGroup"):
+			_, _ = w.Write([]byte(`{"ok":true,"result":[]}`))
+		case strings.HasPrefix(r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:], "send"):
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":1,"date":0,"chat":{"id":1,"type":"private"}}}`))
+		default:
+			_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+		}
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func TestLocalServerFilePaths(t *testing.T) {
+	srv := newRecordingServer(t)
+
+	bot, err := NewBotAPIWithLocalServer("token", srv.URL+"/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bot.IsLocalServer() {
+		t.Fatal("bot is not in local mode")
+	}
+
+	if _, err := bot.Send(NewDocument(int64(1), FilePath("tests/image.jpg"))); err != nil {
+		t.Fatal(err)
+	}
+
+	abs, _ := filepath.Abs("tests/image.jpg")
+	want := "file://" + filepath.ToSlash(abs)
+
+	if got := srv.params[1]["document"]; got != want {
+		t.Fatalf("expected document %q, got %q", want, got)
+	}
+
+	_, err = bot.SendMediaGroup(NewMediaGroup(int64(1), []InputMedia{
+		NewInputMediaPhoto(FilePath("tests/image.jpg")),
+		NewInputMediaPhoto(FileID("id")),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	media := srv.params[2]["media"]
+	if !strings.Contains(media, `"media":"`+want+`"`) || strings.Contains(media, "attach://") {
+		t.Fatalf("unexpected media %s", media)
+	}
+	if _, ok := srv.params[2]["file-0"]; ok {
+		t.Fatal("attached file was also sent as a parameter")
+	}
+}
+
+func TestMigrateToLocalServer(t *testing.T) {
+	cloud := newRecordingServer(t)
+	local := newRecordingServer(t)
+	other := newRecordingServer(t)
+
+	bot, err := NewBotAPIWithAPIEndpoint("token", cloud.URL+"/bot%s/%s")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bot.MigrateToLocalServer(context.Background(), local.URL); err != nil {
+		t.Fatal(err)
+	}
+	if err := bot.MigrateToLocalServer(context.Background(), other.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(cloud.calls, ",") != "getMe,logOut" {
+		t.Fatalf("unexpected cloud calls %v", cloud.calls)
+	}
+	if strings.Join(local.calls, ",") != "getMe,deleteWebhook,close" {
+		t.Fatalf("unexpected local calls %v", local.calls)
+	}
+	if strings.Join(other.calls, ",") != "getMe" {
+		t.Fatalf("unexpected calls %v", other.calls)
+	}
+}
+
+func TestMigrateToCloudFromCloud(t *testing.T) {
+	cloud := newRecordingServer(t)
+
+	bot, err := NewBotAPIWithAPIEndpoint("token", cloud.URL+"/bot%s/%s")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bot.MigrateToCloud(context.Background()); err == nil {
+		t.Fatal("expected an error for a bot not using a local server")
+	}
+	if strings.Join(cloud.calls, ",") != "getMe" {
+		t.Fatalf("unexpected cloud calls %v", cloud.calls)
+	}
+}
@@ -0,0 +1,43 @@
+package tgbotapi
+
+import (
+	"errors"
+	"strconv"
+)
+
+// chatMigrated handles a request that failed with err because the group in
+// its chat_id was upgraded to a supergroup.
+//
+// It calls bot.OnChatMigrated and, if bot.FollowChatMigrations is set,
+// returns a copy of params addressed to the new supergroup.
+func (bot *BotAPI) chatMigrated(params Params, err error) (Params, bool) {
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.MigrateToChatID == 0 {
+		return nil, false
+	}
+
+	chatID, parseErr := strconv.ParseInt(params["chat_id"], 10, 64)
+	if parseErr != nil || chatID == apiErr.MigrateToChatID {
+		return nil, false
+	}
+
+	if bot.Debug {
+		log.Printf("Chat %d was migrated to %d\n", chatID, apiErr.MigrateToChatID)
+	}
+
+	if bot.OnChatMigrated != nil {
+		bot.OnChatMigrated(chatID, apiErr.MigrateToChatID)
+	}
+
+	if !bot.FollowChatMigrations {
+		return nil, false
+	}
+
+	migrated := make(Params, len(params))
+	for key, value := range params {
+		migrated[key] = value
+	}
+	migrated.AddNonZero64("chat_id", apiErr.MigrateToChatID)
+
+	return migrated, true
+}
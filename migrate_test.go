@@ -0,0 +1,78 @@
+package tgbotapi
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func migratingHandler(t *testing.T, chatIDs *[]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+			t.Error(err)
+		}
+
+		chatID := r.FormValue("chat_id")
+		*chatIDs = append(*chatIDs, chatID)
+
+		if chatID == "-123" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: group chat was upgraded to a supergroup chat","parameters":{"migrate_to_chat_id":-100123}}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":1,"date":0,"chat":{"id":-100123,"type":"supergroup"}}}`))
+	}
+}
+
+func TestFollowChatMigrations(t *testing.T) {
+	var chatIDs []string
+	bot := newLocalBot(t, migratingHandler(t, &chatIDs))
+	bot.FollowChatMigrations = true
+
+	var oldID, newID int64
+	bot.OnChatMigrated = func(oldChatID, newChatID int64) {
+		oldID, newID = oldChatID, newChatID
+	}
+
+	msg, err := bot.Send(NewMessage(int64(-123), "hi"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Chat.ID != -100123 {
+		t.Fatalf("unexpected chat %d", msg.Chat.ID)
+	}
+	if oldID != -123 || newID != -100123 {
+		t.Fatalf("unexpected migration %d -> %d", oldID, newID)
+	}
+
+	_, err = bot.Send(NewDocument(int64(-123), FileBytes{Name: "doc.txt", Bytes: []byte("doc")}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(chatIDs) != 4 || chatIDs[2] != "-123" || chatIDs[3] != "-100123" {
+		t.Fatalf("unexpected requests %v", chatIDs)
+	}
+}
+
+func TestChatMigrationsNotFollowed(t *testing.T) {
+	var chatIDs []string
+	bot := newLocalBot(t, migratingHandler(t, &chatIDs))
+
+	called := false
+	bot.OnChatMigrated = func(oldChatID, newChatID int64) {
+		called = true
+	}
+
+	_, err := bot.Send(NewMessage(int64(-123), "hi"))
+	if !errors.Is(err, ErrChatMigrated) {
+		t.Fatalf("expected ErrChatMigrated, got %v", err)
+	}
+	if !called {
+		t.Fatal("OnChatMigrated was not called")
+	}
+	if len(chatIDs) != 1 {
+		t.Fatalf("unexpected requests %v", chatIDs)
+	}
+}
@@ -0,0 +1,205 @@
+package tgbotapi
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OffsetStore persists the offset of the next update to receive, so that
+// polling resumes where it left off after a restart.
+type OffsetStore interface {
+	// LoadOffset returns the stored offset, or 0 if none is stored.
+	LoadOffset(ctx context.Context) (int, error)
+	// SaveOffset stores the offset.
+	SaveOffset(ctx context.Context, offset int) error
+}
+
+// FileOffsetStore is an OffsetStore keeping the offset in a file. The file
+// is replaced atomically, so it is never left half written.
+type FileOffsetStore struct {
+	path string
+}
+
+// NewFileOffsetStore creates a FileOffsetStore keeping the offset at path.
+func NewFileOffsetStore(path string) *FileOffsetStore {
+	return &FileOffsetStore{path: path}
+}
+
+// LoadOffset returns the offset stored in the file, or 0 if it doesn't
+// exist.
+func (s *FileOffsetStore) LoadOffset(ctx context.Context) (int, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
+
+// SaveOffset writes the offset to the file.
+func (s *FileOffsetStore) SaveOffset(ctx context.Context, offset int) error {
+	return writeFileAtomic(s.path, []byte(strconv.Itoa(offset)))
+}
+
+// StorageOffsetStore is an OffsetStore keeping the offset in a Storage.
+type StorageOffsetStore struct {
+	Storage Storage
+	Key     string
+}
+
+// NewStorageOffsetStore creates an OffsetStore keeping the offset in storage
+// under the key "updates_offset".
+func NewStorageOffsetStore(storage Storage) *StorageOffsetStore {
+	return &StorageOffsetStore{Storage: storage, Key: "updates_offset"}
+}
+
+// LoadOffset returns the stored offset, or 0 if none is stored.
+func (s *StorageOffsetStore) LoadOffset(ctx context.Context) (int, error) {
+	data, ok, err := s.Storage.Get(ctx, s.Key)
+	if err != nil || !ok {
+		return 0, err
+	}
+
+	return strconv.Atoi(string(data))
+}
+
+// SaveOffset stores the offset.
+func (s *StorageOffsetStore) SaveOffset(ctx context.Context, offset int) error {
+	return s.Storage.Set(ctx, s.Key, []byte(strconv.Itoa(offset)), 0)
+}
+
+// pendingPollInterval is how often updates are polled while all the updates
+// Telegram returns are waiting to be acknowledged.
+var pendingPollInterval = time.Second
+
+// updateAcks tracks the updates delivered by GetUpdatesChan while an
+// OffsetStore is set. The committed offset only moves past an update once
+// it and all updates before it were acknowledged.
+type updateAcks struct {
+	store OffsetStore
+
+	mu        sync.Mutex
+	delivered []int
+	acked     map[int]bool
+	next      int
+	committed int
+	changed   chan struct{}
+}
+
+func newUpdateAcks(store OffsetStore, offset int) *updateAcks {
+	return &updateAcks{
+		store:     store,
+		acked:     make(map[int]bool),
+		next:      offset,
+		committed: offset,
+		changed:   make(chan struct{}, 1),
+	}
+}
+
+// isNew reports whether the update wasn't delivered yet.
+func (a *updateAcks) isNew(updateID int) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return updateID >= a.next
+}
+
+// deliver records that the update was delivered.
+func (a *updateAcks) deliver(updateID int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.delivered = append(a.delivered, updateID)
+	a.next = updateID + 1
+}
+
+// offset returns the committed offset.
+func (a *updateAcks) offset() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.committed
+}
+
+// wait waits until the committed offset moves or pendingPollInterval
+// passes. It returns false if ctx is done first.
+func (a *updateAcks) wait(ctx context.Context) bool {
+	timer := time.NewTimer(pendingPollInterval)
+	defer timer.Stop()
+
+	select {
+	case <-a.changed:
+		return true
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// ack records that the update was handled, saving the committed offset if
+// it moved.
+func (a *updateAcks) ack(ctx context.Context, updateID int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if updateID < a.committed || updateID >= a.next {
+		return nil
+	}
+
+	a.acked[updateID] = true
+
+	committed := a.committed
+	for len(a.delivered) > 0 && a.acked[a.delivered[0]] {
+		delete(a.acked, a.delivered[0])
+		a.delivered = a.delivered[1:]
+	}
+
+	a.committed = a.next
+	if len(a.delivered) > 0 {
+		a.committed = a.delivered[0]
+	}
+
+	if a.committed == committed {
+		return nil
+	}
+
+	select {
+	case a.changed <- struct{}{}:
+	default:
+	}
+
+	return a.store.SaveOffset(ctx, a.committed)
+}
+
+// AckUpdate acknowledges that an update received from a channel was
+// handled. It does nothing unless OffsetStore or UpdateIDStore is set.
+//
+// With an UpdateIDStore, the update is recorded there so that it is dropped
+// if Telegram delivers it again. With an OffsetStore, the stored offset and
+// the offset confirmed to Telegram only move past updates once they and all
+// updates before them were acknowledged. Updates that were not are received
+// again after a restart. Dispatcher.Run acknowledges updates once they are
+// handled.
+func (bot *BotAPI) AckUpdate(update Update) error {
+	return bot.AckUpdateContext(context.Background(), update)
+}
+
+// AckUpdateContext is the same as AckUpdate except it accepts a context.
+func (bot *BotAPI) AckUpdateContext(ctx context.Context, update Update) error {
+	err := bot.updateHandled(ctx, update)
+
+	if acks := bot.acks.Load(); acks != nil {
+		err = errors.Join(err, acks.ack(ctx, update.UpdateID))
+	}
+
+	return err
+}
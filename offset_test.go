@@ -0,0 +1,192 @@
+package tgbotapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGetUpdatesChanOffsetStore(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		offsets []int
+	)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.FormValue("offset"))
+
+		mu.Lock()
+		offsets = append(offsets, offset)
+		mu.Unlock()
+
+		var updates []string
+		for id := max(offset, 1); id <= 3; id++ {
+			updates = append(updates, fmt.Sprintf(`{"update_id":%d}`, id))
+		}
+
+		if len(updates) == 0 {
+			<-r.Context().Done()
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"ok":true,"result":[` + strings.Join(updates, ",") + `]}`))
+	}
+
+	store := NewFileOffsetStore(filepath.Join(t.TempDir(), "offset"))
+
+	bot := newLocalBot(t, handler)
+	bot.OffsetStore = store
+
+	updates := bot.GetUpdatesChan(NewUpdate(0))
+
+	received := make([]Update, 3)
+	for i := range received {
+		received[i] = <-updates
+	}
+
+	stored := func() int {
+		offset, err := store.LoadOffset(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return offset
+	}
+
+	if err := bot.AckUpdate(received[1]); err != nil {
+		t.Fatal(err)
+	}
+	if offset := stored(); offset > 1 {
+		t.Fatalf("offset moved past an unacknowledged update: %d", offset)
+	}
+
+	if err := bot.AckUpdate(received[0]); err != nil {
+		t.Fatal(err)
+	}
+	if offset := stored(); offset != 3 {
+		t.Fatalf("expected offset 3, got %d", offset)
+	}
+
+	bot.StopReceivingUpdates()
+
+	// Update 3 was never acknowledged, so it is received again.
+	bot = newLocalBot(t, handler)
+	bot.OffsetStore = store
+
+	updates = bot.GetUpdatesChan(NewUpdate(0))
+
+	if update := <-updates; update.UpdateID != 3 {
+		t.Fatalf("expected update 3 again, got %d", update.UpdateID)
+	}
+	if err := bot.AckUpdate(Update{UpdateID: 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		last := offsets[len(offsets)-1]
+		mu.Unlock()
+
+		if last == 4 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("offset 4 was never confirmed, last offset %d", last)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	bot.StopReceivingUpdates()
+
+	if offset := stored(); offset != 4 {
+		t.Fatalf("expected offset 4, got %d", offset)
+	}
+}
+
+func TestGetUpdatesChanLoadedOffset(t *testing.T) {
+	bot := newLocalBot(t, func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	bot.OffsetStore = NewStorageOffsetStore(NewMemoryStorage())
+
+	if err := bot.OffsetStore.SaveOffset(context.Background(), 5); err != nil {
+		t.Fatal(err)
+	}
+
+	bot.GetUpdatesChan(NewUpdate(0))
+	defer bot.StopReceivingUpdates()
+
+	if offset := bot.UpdatesOffset(); offset != 5 {
+		t.Fatalf("expected offset 5, got %d", offset)
+	}
+}
+
+func TestGetUpdatesChanRestartKeepsUnackedUpdates(t *testing.T) {
+	bot := newLocalBot(t, func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.FormValue("offset"))
+
+		var updates []string
+		for id := max(offset, 1); id <= 3; id++ {
+			updates = append(updates, fmt.Sprintf(`{"update_id":%d}`, id))
+		}
+
+		if len(updates) == 0 {
+			<-r.Context().Done()
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"ok":true,"result":[` + strings.Join(updates, ",") + `]}`))
+	})
+	bot.OffsetStore = NewStorageOffsetStore(NewMemoryStorage())
+
+	updates := bot.GetUpdatesChan(NewUpdate(0))
+	first := <-updates
+	<-updates
+	<-updates
+
+	if err := bot.AckUpdate(first); err != nil {
+		t.Fatal(err)
+	}
+
+	bot.StopReceivingUpdates()
+
+	if offset := bot.UpdatesOffset(); offset != 2 {
+		t.Fatalf("expected the committed offset 2, got %d", offset)
+	}
+
+	// A caller resuming past the unacknowledged updates still receives them.
+	updates = bot.GetUpdatesChan(NewUpdate(4))
+	defer bot.StopReceivingUpdates()
+
+	select {
+	case update := <-updates:
+		if update.UpdateID != 2 {
+			t.Fatalf("expected update 2 again, got %d", update.UpdateID)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("unacknowledged updates were not received again")
+	}
+}
+
+func TestStorageOffsetStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewStorageOffsetStore(NewMemoryStorage())
+
+	if offset, err := store.LoadOffset(ctx); err != nil || offset != 0 {
+		t.Fatalf("expected no offset, got %d, %v", offset, err)
+	}
+
+	if err := store.SaveOffset(ctx, 42); err != nil {
+		t.Fatal(err)
+	}
+
+	if offset, err := store.LoadOffset(ctx); err != nil || offset != 42 {
+		t.Fatalf("expected offset 42, got %d, %v", offset, err)
+	}
+}
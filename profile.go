@@ -0,0 +1,177 @@
+package tgbotapi
+
+import (
+	"context"
+	"slices"
+	"sort"
+)
+
+// BotProfileStrings are the profile strings of a bot for one language.
+// Empty strings remove the dedicated strings of the language.
+//
+// Telegram reports the default strings for a language without its own, so
+// SyncProfile can't tell a removed string from one equal to the default.
+type BotProfileStrings struct {
+	Name             string
+	Description      string
+	ShortDescription string
+}
+
+// BotProfile declares the profile of a bot, which SyncProfile applies.
+type BotProfile struct {
+	// Languages maps language codes to the profile strings shown to users
+	// with that language. The empty code holds the default strings.
+	Languages map[string]BotProfileStrings
+	// Commands holds the commands for each scope and language. A config
+	// without commands deletes the commands of its scope and language.
+	Commands []SetMyCommandsConfig
+}
+
+// SyncProfile compares the declared profile with the one Telegram reports
+// and only makes the calls needed to apply the differences. It returns the
+// configs it applied.
+//
+// Languages and scopes not in the profile are left untouched.
+func (bot *BotAPI) SyncProfile(ctx context.Context, profile BotProfile) ([]Chattable, error) {
+	var applied []Chattable
+
+	apply := func(c Chattable) error {
+		if _, err := bot.RequestContext(ctx, c); err != nil {
+			return err
+		}
+
+		applied = append(applied, c)
+
+		return nil
+	}
+
+	var defaults *BotProfileStrings
+
+	// unchanged reports whether the current string of a language matches
+	// the wanted one. An empty string also matches the default string,
+	// which Telegram reports for languages without their own.
+	unchanged := func(language, current, want string, field func(BotProfileStrings) string) (bool, error) {
+		if current == want {
+			return true, nil
+		}
+		if want != "" || language == "" {
+			return false, nil
+		}
+
+		if defaults == nil {
+			current, err := bot.getProfileStrings(ctx, profile)
+			if err != nil {
+				return false, err
+			}
+			defaults = &current
+		}
+
+		return current == field(*defaults), nil
+	}
+
+	languages := make([]string, 0, len(profile.Languages))
+	for language := range profile.Languages {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+
+	for _, language := range languages {
+		want := profile.Languages[language]
+
+		name, err := bot.GetMyNameContext(ctx, GetMyNameConfig{LanguageCode: language})
+		if err != nil {
+			return applied, err
+		}
+		same, err := unchanged(language, name.Name, want.Name, func(s BotProfileStrings) string { return s.Name })
+		if err != nil {
+			return applied, err
+		}
+		if !same {
+			if err := apply(SetMyNameConfig{Name: want.Name, LanguageCode: language}); err != nil {
+				return applied, err
+			}
+		}
+
+		description, err := bot.GetMyDescriptionContext(ctx, GetMyDescriptionConfig{LanguageCode: language})
+		if err != nil {
+			return applied, err
+		}
+		same, err = unchanged(language, description.Description, want.Description, func(s BotProfileStrings) string { return s.Description })
+		if err != nil {
+			return applied, err
+		}
+		if !same {
+			if err := apply(SetMyDescriptionConfig{Description: want.Description, LanguageCode: language}); err != nil {
+				return applied, err
+			}
+		}
+
+		short, err := bot.GetMyShortDescriptionContext(ctx, GetMyShortDescriptionConfig{LanguageCode: language})
+		if err != nil {
+			return applied, err
+		}
+		same, err = unchanged(language, short.ShortDescription, want.ShortDescription, func(s BotProfileStrings) string { return s.ShortDescription })
+		if err != nil {
+			return applied, err
+		}
+		if !same {
+			if err := apply(SetMyShortDescriptionConfig{ShortDescription: want.ShortDescription, LanguageCode: language}); err != nil {
+				return applied, err
+			}
+		}
+	}
+
+	for _, config := range profile.Commands {
+		current, err := bot.GetMyCommandsWithConfigContext(ctx, GetMyCommandsConfig{
+			Scope:        config.Scope,
+			LanguageCode: config.LanguageCode,
+		})
+		if err != nil {
+			return applied, err
+		}
+
+		if slices.Equal(current, config.Commands) {
+			continue
+		}
+
+		var c Chattable = config
+		if len(config.Commands) == 0 {
+			c = DeleteMyCommandsConfig{Scope: config.Scope, LanguageCode: config.LanguageCode}
+		}
+
+		if err := apply(c); err != nil {
+			return applied, err
+		}
+	}
+
+	return applied, nil
+}
+
+// getProfileStrings returns the default strings of the bot. When the profile
+// declares them, SyncProfile has applied them before they are needed.
+func (bot *BotAPI) getProfileStrings(ctx context.Context, profile BotProfile) (BotProfileStrings, error) {
+	if declared, ok := profile.Languages[""]; ok {
+		return declared, nil
+	}
+
+	name, err := bot.GetMyNameContext(ctx, GetMyNameConfig{})
+	if err != nil {
+		return BotProfileStrings{}, err
+	}
+
+	description, err := bot.GetMyDescriptionContext(ctx, GetMyDescriptionConfig{})
+	if err != nil {
+		return BotProfileStrings{}, err
+	}
+
+	short, err := bot.GetMyShortDescriptionContext(ctx, GetMyShortDescriptionConfig{})
+	if err != nil {
+		return BotProfileStrings{}, err
+	}
+
+	return BotProfileStrings{
+		Name:             name.Name,
+		Description:      description.Description,
+		ShortDescription: short.ShortDescription,
+	}, nil
+}
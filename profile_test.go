@@ -0,0 +1,128 @@
+package tgbotapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path"
+	"sync"
+	"testing"
+)
+
+func TestSyncProfile(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		state    = map[string]string{"name:": "Bot", "description:": "A bot"}
+		commands = map[string]string{}
+		calls    []string
+	)
+
+	bot := newLocalBot(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		method := path.Base(r.URL.Path)
+		language := r.FormValue("language_code")
+		commandsKey := r.FormValue("scope") + language
+
+		var result any = true
+
+		// Like Telegram, fall back to the default strings for languages
+		// without their own.
+		get := func(key string) string {
+			if value, ok := state[key+language]; ok {
+				return value
+			}
+			return state[key]
+		}
+		set := func(key, value string) {
+			if value == "" && language != "" {
+				delete(state, key+language)
+				return
+			}
+			state[key+language] = value
+		}
+
+		switch method {
+		case "getMyName":
+			result = BotName{Name: get("name:")}
+		case "getMyDescription":
+			result = BotDescription{Description: get("description:")}
+		case "getMyShortDescription":
+			result = BotShortDescription{ShortDescription: get("short:")}
+		case "getMyCommands":
+			result = json.RawMessage(commands[commandsKey])
+			if commands[commandsKey] == "" {
+				result = []BotCommand{}
+			}
+		case "setMyName":
+			set("name:", r.FormValue("name"))
+		case "setMyDescription":
+			set("description:", r.FormValue("description"))
+		case "setMyShortDescription":
+			set("short:", r.FormValue("short_description"))
+		case "setMyCommands":
+			commands[commandsKey] = r.FormValue("commands")
+		case "deleteMyCommands":
+			delete(commands, commandsKey)
+		}
+
+		if method[:3] != "get" {
+			calls = append(calls, method+":"+language)
+		}
+
+		data, _ := json.Marshal(result)
+		_, _ = w.Write([]byte(`{"ok":true,"result":` + string(data) + `}`))
+	})
+
+	profile := BotProfile{
+		Languages: map[string]BotProfileStrings{
+			"":   {Name: "Bot", Description: "A bot", ShortDescription: "Short"},
+			"ru": {Name: "Бот", Description: "Бот"},
+		},
+		Commands: []SetMyCommandsConfig{
+			NewSetMyCommands(BotCommand{Command: "start", Description: "Start the bot"}),
+			NewSetMyCommandsWithScopeAndLanguage(NewBotCommandScopeAllGroupChats(), "ru"),
+		},
+	}
+
+	applied, err := bot.SyncProfile(context.Background(), profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"setMyShortDescription:", "setMyName:ru", "setMyDescription:ru", "setMyCommands:"}
+	if len(calls) != len(want) || len(applied) != len(want) {
+		t.Fatalf("expected calls %v, got %v", want, calls)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Fatalf("expected calls %v, got %v", want, calls)
+		}
+	}
+
+	calls = nil
+
+	if applied, err := bot.SyncProfile(context.Background(), profile); err != nil || len(applied) != 0 {
+		t.Fatalf("expected no changes, got %v, %v (calls %v)", applied, err, calls)
+	}
+
+	// Removing the dedicated strings of a language makes Telegram report
+	// the default ones, which must not be applied again on the next sync.
+	profile = BotProfile{Languages: map[string]BotProfileStrings{"ru": {}}}
+
+	calls = nil
+
+	if _, err := bot.SyncProfile(context.Background(), profile); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 || calls[0] != "setMyName:ru" || calls[1] != "setMyDescription:ru" {
+		t.Fatalf("expected the ru strings to be removed, got %v", calls)
+	}
+
+	calls = nil
+
+	if applied, err := bot.SyncProfile(context.Background(), profile); err != nil || len(applied) != 0 {
+		t.Fatalf("expected no changes, got %v, %v (calls %v)", applied, err, calls)
+	}
+}
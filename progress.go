@@ -0,0 +1,157 @@
+package tgbotapi
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// UploadProgressFunc is called while files are uploaded with the number of
+// bytes of the files written so far and their total size, or -1 if it isn't
+// known.
+//
+// It runs on the goroutine writing the upload, not the one making the
+// request, so it must be safe to call concurrently with the rest of the
+// program.
+//
+// The Bot API can't resume uploads, so a retried upload starts again from
+// the first byte and the callback is called again from zero.
+type UploadProgressFunc func(written, total int64)
+
+type uploadProgressKey struct{}
+
+// WithUploadProgress returns a context making uploads of requests using it
+// report their progress to fn.
+func WithUploadProgress(ctx context.Context, fn UploadProgressFunc) context.Context {
+	return context.WithValue(ctx, uploadProgressKey{}, fn)
+}
+
+// uploadProgress counts the bytes of files written to a request.
+type uploadProgress struct {
+	fn      UploadProgressFunc
+	written int64
+	total   int64
+}
+
+// newUploadProgress returns an uploadProgress for the files if ctx has a
+// progress callback, and nil otherwise.
+func newUploadProgress(ctx context.Context, files []RequestFile) *uploadProgress {
+	fn, ok := ctx.Value(uploadProgressKey{}).(UploadProgressFunc)
+	if !ok || fn == nil {
+		return nil
+	}
+
+	return &uploadProgress{fn: fn, total: uploadSize(files)}
+}
+
+// reader wraps r to count the bytes read from it.
+func (p *uploadProgress) reader(r io.Reader) io.Reader {
+	if p == nil {
+		return r
+	}
+
+	return &progressReader{r: r, p: p}
+}
+
+type progressReader struct {
+	r io.Reader
+	p *uploadProgress
+}
+
+func (r *progressReader) Read(b []byte) (int, error) {
+	n, err := r.r.Read(b)
+	if n > 0 {
+		r.p.written += int64(n)
+		r.p.fn(r.p.written, r.p.total)
+	}
+
+	return n, err
+}
+
+// uploadSize returns the total size of the files to upload, or -1 if the
+// size of one of them isn't known.
+func uploadSize(files []RequestFile) int64 {
+	var total int64
+
+	for _, file := range files {
+		if !file.Data.NeedsUpload() {
+			continue
+		}
+
+		switch data := file.Data.(type) {
+		case FileBytes:
+			total += int64(len(data.Bytes))
+		case FilePath:
+			info, err := os.Stat(string(data))
+			if err != nil {
+				return -1
+			}
+			total += info.Size()
+		default:
+			return -1
+		}
+	}
+
+	return total
+}
+
+// uploadChatActionInterval is how often the chat action is repeated during
+// an upload. Telegram shows it for 5 seconds.
+var uploadChatActionInterval = 4 * time.Second
+
+// uploadChatActions maps upload methods to the chat action shown while they
+// run.
+var uploadChatActions = map[string]string{
+	"sendPhoto":      ChatUploadPhoto,
+	"sendVideo":      ChatUploadVideo,
+	"sendAnimation":  ChatUploadVideo,
+	"sendVideoNote":  ChatUploadVideoNote,
+	"sendAudio":      ChatUploadDocument,
+	"sendVoice":      ChatUploadVoice,
+	"sendDocument":   ChatUploadDocument,
+	"sendMediaGroup": ChatUploadDocument,
+}
+
+// startUploadChatAction sends the chat action matching the upload method to
+// the chat of the request until the returned function is called, if
+// bot.UploadChatActions is set.
+func (bot *BotAPI) startUploadChatAction(ctx context.Context, endpoint string, params Params) func() {
+	action, ok := uploadChatActions[endpoint]
+	if !bot.UploadChatActions || !ok || params["chat_id"] == "" {
+		return func() {}
+	}
+
+	actionParams := Params{"chat_id": params["chat_id"], "action": action}
+	actionParams.AddNonEmpty("message_thread_id", params["message_thread_id"])
+
+	ctx, cancel := context.WithCancel(ctx)
+	var done atomic.Bool
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(uploadChatActionInterval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := bot.MakeRequestContext(ctx, "sendChatAction", actionParams); err != nil && !done.Load() && bot.Debug {
+				log.Printf("Failed to send chat action: %v\n", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return func() {
+		done.Store(true)
+		cancel()
+		<-stopped
+	}
+}
@@ -0,0 +1,85 @@
+package tgbotapi
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestUploadProgress(t *testing.T) {
+	bot := newLocalBot(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":1,"date":0,"chat":{"id":1,"type":"private"}}}`))
+	})
+
+	data := bytes.Repeat([]byte("a"), 100000)
+
+	var written, total int64
+	ctx := WithUploadProgress(context.Background(), func(w, t int64) {
+		written, total = w, t
+	})
+
+	if _, err := bot.SendContext(ctx, NewDocument(int64(1), FileBytes{Name: "a.txt", Bytes: data})); err != nil {
+		t.Fatal(err)
+	}
+	if written != int64(len(data)) || total != int64(len(data)) {
+		t.Fatalf("unexpected progress %d/%d", written, total)
+	}
+
+	reader := FileReader{Name: "a.txt", Reader: io.NopCloser(bytes.NewReader(data))}
+	if _, err := bot.SendContext(ctx, NewDocument(int64(1), reader)); err != nil {
+		t.Fatal(err)
+	}
+	if written != int64(len(data)) || total != -1 {
+		t.Fatalf("unexpected progress %d/%d", written, total)
+	}
+}
+
+func TestUploadChatActions(t *testing.T) {
+	defer func(interval time.Duration) {
+		uploadChatActionInterval = interval
+	}(uploadChatActionInterval)
+	uploadChatActionInterval = 20 * time.Millisecond
+
+	var mu sync.Mutex
+	var actions []string
+
+	bot := newLocalBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/sendChatAction") {
+			_ = r.ParseForm()
+
+			mu.Lock()
+			actions = append(actions, r.PostForm.Get("chat_id")+":"+r.PostForm.Get("action"))
+			mu.Unlock()
+
+			_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+			return
+		}
+
+		_, _ = io.Copy(io.Discard, r.Body)
+		time.Sleep(70 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":1,"date":0,"chat":{"id":5,"type":"private"}}}`))
+	})
+	bot.UploadChatActions = true
+
+	if _, err := bot.Send(NewVideo(int64(5), FileBytes{Name: "a.mp4", Bytes: []byte("video")})); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if len(actions) < 2 {
+		t.Fatalf("expected repeated chat actions, got %v", actions)
+	}
+	for _, action := range actions {
+		if action != "5:"+ChatUploadVideo {
+			t.Fatalf("unexpected chat action %s", action)
+		}
+	}
+}
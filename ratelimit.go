@@ -0,0 +1,373 @@
+package tgbotapi
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimiter paces outgoing requests.
+//
+// Wait is called before every request, including retries, and blocks until
+// the request may be sent or ctx is done.
+type RateLimiter interface {
+	Wait(ctx context.Context, method string, params Params) error
+}
+
+// Rate is a limit of requests per time period.
+type Rate struct {
+	Limit int
+	Per   time.Duration
+}
+
+// unlimited reports whether the rate doesn't limit anything.
+func (r Rate) unlimited() bool {
+	return r.Limit <= 0 || r.Per <= 0
+}
+
+// RateLimiterStats describes the time requests spent waiting in a
+// ChatRateLimiter.
+type RateLimiterStats struct {
+	// Requests is the number of requests that passed the limiter.
+	Requests uint64
+	// Delayed is the number of requests that had to wait.
+	Delayed uint64
+	// TotalWait is the total time requests spent waiting.
+	TotalWait time.Duration
+	// MaxWait is the longest time a request spent waiting.
+	MaxWait time.Duration
+}
+
+// ChatRateLimiter limits messages sent to Telegram globally and per chat, the
+// chat being taken from the chat_id of the request.
+//
+// Only methods sending messages are limited. When requests have to wait,
+// chats take turns so a large backlog for one chat doesn't delay the others.
+type ChatRateLimiter struct {
+	// Global limits messages across all chats.
+	Global Rate
+	// Private limits messages to a single private chat.
+	Private Rate
+	// Group limits messages to a single group, supergroup or channel.
+	Group Rate
+
+	// OnWait is called for every request that passed the limiter with the
+	// chat it was sent to and the time it waited.
+	OnWait func(chatID string, wait time.Duration)
+
+	mu        sync.Mutex
+	sent      []time.Time
+	chatSent  map[string][]time.Time
+	queues    map[string]*limiterQueue
+	order     []string
+	wake      chan struct{}
+	running   bool
+	lastSweep time.Time
+	stats     RateLimiterStats
+}
+
+type limiterQueue struct {
+	rate    Rate
+	waiters []*limiterWaiter
+}
+
+type limiterWaiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+// NewChatRateLimiter creates a ChatRateLimiter with the limits documented by
+// Telegram: about 30 messages per second overall, 1 message per second to a
+// private chat and 20 messages per minute to a group.
+func NewChatRateLimiter() *ChatRateLimiter {
+	return &ChatRateLimiter{
+		Global:  Rate{Limit: 30, Per: time.Second},
+		Private: Rate{Limit: 1, Per: time.Second},
+		Group:   Rate{Limit: 20, Per: time.Minute},
+	}
+}
+
+// Stats returns statistics about the requests that passed the limiter.
+func (l *ChatRateLimiter) Stats() RateLimiterStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.stats
+}
+
+// Wait blocks until a message to the chat in params may be sent.
+func (l *ChatRateLimiter) Wait(ctx context.Context, method string, params Params) error {
+	if !isMessageMethod(method) {
+		return nil
+	}
+
+	chatID := params["chat_id"]
+	now := time.Now()
+
+	l.mu.Lock()
+
+	if l.chatSent == nil {
+		l.chatSent = make(map[string][]time.Time)
+		l.queues = make(map[string]*limiterQueue)
+		l.wake = make(chan struct{}, 1)
+	}
+
+	rate := l.chatRate(chatID)
+
+	// Requests go straight through unless someone is already waiting.
+	if len(l.order) == 0 && !l.nextFree(l.sent, l.Global, now).After(now) &&
+		!l.nextFree(l.chatSent[chatID], rate, now).After(now) {
+		l.record(chatID, rate, now)
+		l.mu.Unlock()
+
+		l.observe(chatID, 0)
+
+		return nil
+	}
+
+	waiter := &limiterWaiter{ready: make(chan struct{})}
+
+	q, ok := l.queues[chatID]
+	if !ok {
+		q = &limiterQueue{rate: rate}
+		l.queues[chatID] = q
+		l.order = append(l.order, chatID)
+	}
+	q.waiters = append(q.waiters, waiter)
+
+	if !l.running {
+		l.running = true
+		go l.run()
+	} else {
+		select {
+		case l.wake <- struct{}{}:
+		default:
+		}
+	}
+
+	l.mu.Unlock()
+
+	select {
+	case <-waiter.ready:
+		l.observe(chatID, time.Since(now))
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if waiter.granted {
+			// The slot was already taken, so let the request through.
+			return nil
+		}
+
+		l.remove(chatID, waiter)
+
+		return ctx.Err()
+	}
+}
+
+// run grants waiting requests until there are none left.
+func (l *ChatRateLimiter) run() {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		l.mu.Lock()
+		if len(l.order) == 0 {
+			l.running = false
+			l.sweep(time.Now())
+			l.mu.Unlock()
+			return
+		}
+
+		next := l.grant(time.Now())
+		l.mu.Unlock()
+
+		timer.Reset(time.Until(next))
+
+		select {
+		case <-timer.C:
+		case <-l.wake:
+		}
+	}
+}
+
+// grant releases as many waiters as the limits allow, visiting chats in
+// turn, and returns when the next one might be released.
+func (l *ChatRateLimiter) grant(now time.Time) time.Time {
+	for len(l.order) > 0 {
+		next := l.nextFree(l.sent, l.Global, now)
+		if next.After(now) {
+			return next
+		}
+
+		var earliest time.Time
+		granted := false
+
+		for i, chatID := range l.order {
+			q := l.queues[chatID]
+
+			free := l.nextFree(l.chatSent[chatID], q.rate, now)
+			if free.After(now) {
+				if earliest.IsZero() || free.Before(earliest) {
+					earliest = free
+				}
+				continue
+			}
+
+			waiter := q.waiters[0]
+			q.waiters = q.waiters[1:]
+			waiter.granted = true
+			close(waiter.ready)
+
+			l.record(chatID, q.rate, now)
+
+			// Move the chat to the back of the line.
+			l.order = append(l.order[:i], l.order[i+1:]...)
+			if len(q.waiters) > 0 {
+				l.order = append(l.order, chatID)
+			} else {
+				delete(l.queues, chatID)
+			}
+
+			granted = true
+			break
+		}
+
+		if !granted {
+			return earliest
+		}
+	}
+
+	return now
+}
+
+// remove drops a waiter whose context is done.
+func (l *ChatRateLimiter) remove(chatID string, waiter *limiterWaiter) {
+	q, ok := l.queues[chatID]
+	if !ok {
+		return
+	}
+
+	for i, w := range q.waiters {
+		if w == waiter {
+			q.waiters = append(q.waiters[:i], q.waiters[i+1:]...)
+			break
+		}
+	}
+
+	if len(q.waiters) > 0 {
+		return
+	}
+
+	delete(l.queues, chatID)
+	for i, id := range l.order {
+		if id == chatID {
+			l.order = append(l.order[:i], l.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// nextFree returns when another message may be sent given the times of the
+// previous ones.
+func (l *ChatRateLimiter) nextFree(sent []time.Time, rate Rate, now time.Time) time.Time {
+	if rate.unlimited() || len(sent) < rate.Limit {
+		return now
+	}
+
+	free := sent[len(sent)-rate.Limit].Add(rate.Per)
+	if free.Before(now) {
+		return now
+	}
+
+	return free
+}
+
+// record remembers a message sent at now, forgetting the ones that no longer
+// count towards the limits.
+func (l *ChatRateLimiter) record(chatID string, rate Rate, now time.Time) {
+	l.sent = trimSent(append(l.sent, now), l.Global, now)
+
+	if chatID != "" && !rate.unlimited() {
+		l.chatSent[chatID] = trimSent(append(l.chatSent[chatID], now), rate, now)
+	}
+
+	if now.Sub(l.lastSweep) > time.Minute {
+		l.sweep(now)
+	}
+}
+
+// sweep forgets chats without messages that still count towards their limit.
+func (l *ChatRateLimiter) sweep(now time.Time) {
+	l.lastSweep = now
+
+	for chatID, sent := range l.chatSent {
+		if sent = trimSent(sent, l.chatRate(chatID), now); len(sent) == 0 {
+			delete(l.chatSent, chatID)
+		} else {
+			l.chatSent[chatID] = sent
+		}
+	}
+}
+
+func (l *ChatRateLimiter) observe(chatID string, wait time.Duration) {
+	l.mu.Lock()
+	l.stats.Requests++
+	if wait > 0 {
+		l.stats.Delayed++
+		l.stats.TotalWait += wait
+		if wait > l.stats.MaxWait {
+			l.stats.MaxWait = wait
+		}
+	}
+	onWait := l.OnWait
+	l.mu.Unlock()
+
+	if onWait != nil {
+		onWait(chatID, wait)
+	}
+}
+
+// chatRate returns the limit for a chat, private chats having positive IDs.
+func (l *ChatRateLimiter) chatRate(chatID string) Rate {
+	if chatID == "" {
+		return Rate{}
+	}
+
+	if id, err := strconv.ParseInt(chatID, 10, 64); err == nil && id > 0 {
+		return l.Private
+	}
+
+	return l.Group
+}
+
+// trimSent drops the times that are older than the rate period or beyond the
+// number needed to enforce the limit.
+func trimSent(sent []time.Time, rate Rate, now time.Time) []time.Time {
+	if rate.unlimited() {
+		return nil
+	}
+
+	i := 0
+	for i < len(sent) && (len(sent)-i > rate.Limit || now.Sub(sent[i]) >= rate.Per) {
+		i++
+	}
+
+	return sent[i:]
+}
+
+// isMessageMethod reports whether the method sends a message and therefore
+// counts towards Telegram's broadcast limits.
+func isMessageMethod(method string) bool {
+	switch method {
+	case "sendChatAction":
+		return false
+	case "forwardMessage", "forwardMessages", "copyMessage", "copyMessages":
+		return true
+	default:
+		return strings.HasPrefix(method, "send")
+	}
+}
@@ -0,0 +1,110 @@
+package tgbotapi
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestChatRateLimiterPrivateChat(t *testing.T) {
+	limiter := &ChatRateLimiter{Private: Rate{Limit: 1, Per: 50 * time.Millisecond}}
+	params := Params{"chat_id": "42"}
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(context.Background(), "sendMessage", params); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Fatalf("3 messages to a private chat took only %s", elapsed)
+	}
+
+	stats := limiter.Stats()
+	if stats.Requests != 3 || stats.Delayed != 2 || stats.MaxWait == 0 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestChatRateLimiterIgnoresOtherMethods(t *testing.T) {
+	limiter := &ChatRateLimiter{Global: Rate{Limit: 1, Per: time.Hour}}
+
+	for _, method := range []string{"getMe", "sendChatAction", "answerCallbackQuery"} {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		err := limiter.Wait(ctx, method, Params{"chat_id": "1"})
+		cancel()
+
+		if err != nil {
+			t.Fatalf("%s: %v", method, err)
+		}
+	}
+}
+
+func TestChatRateLimiterFairness(t *testing.T) {
+	limiter := &ChatRateLimiter{Global: Rate{Limit: 1, Per: 20 * time.Millisecond}}
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+
+	send := func(chatID string) {
+		defer wg.Done()
+		if err := limiter.Wait(ctx, "sendMessage", Params{"chat_id": chatID}); err != nil {
+			t.Error(err)
+		}
+		mu.Lock()
+		order = append(order, chatID)
+		mu.Unlock()
+	}
+
+	// Take the only slot so the following requests queue up.
+	if err := limiter.Wait(ctx, "sendMessage", Params{"chat_id": "-1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go send("-1")
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	wg.Add(1)
+	go send("-2")
+
+	wg.Wait()
+
+	for i, chatID := range order {
+		if chatID == "-2" {
+			if i > 1 {
+				t.Fatalf("second chat waited behind the first one's backlog: %v", order)
+			}
+			return
+		}
+	}
+	t.Fatalf("second chat never sent: %v", order)
+}
+
+func TestChatRateLimiterCancel(t *testing.T) {
+	limiter := &ChatRateLimiter{Private: Rate{Limit: 1, Per: time.Hour}}
+	params := Params{"chat_id": "1"}
+
+	if err := limiter.Wait(context.Background(), "sendMessage", params); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := limiter.Wait(ctx, "sendMessage", params); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	// Other chats are not held up by the cancelled request.
+	if err := limiter.Wait(context.Background(), "sendMessage", Params{"chat_id": "2"}); err != nil {
+		t.Fatal(err)
+	}
+}
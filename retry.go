@@ -0,0 +1,202 @@
+package tgbotapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy configures how failed requests are retried.
+//
+// Requests are retried on network errors, server errors and flood control
+// errors. Other client errors, such as a bad request or a blocked bot, are
+// never retried.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts including the first one.
+	// Values below 2 disable retries.
+	MaxAttempts int
+	// MinBackoff is the delay before the first retry. It doubles with every
+	// following attempt.
+	MinBackoff time.Duration
+	// MaxBackoff caps the delay between attempts. It does not apply to the
+	// retry_after delay requested by Telegram, which is always honored.
+	MaxBackoff time.Duration
+	// Jitter randomizes the backoff by up to this fraction of it, from 0 to 1.
+	Jitter float64
+}
+
+// NewRetryPolicy creates a RetryPolicy with maxAttempts and default backoff
+// settings.
+func NewRetryPolicy(maxAttempts int) *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: maxAttempts,
+		MinBackoff:  500 * time.Millisecond,
+		MaxBackoff:  30 * time.Second,
+		Jitter:      0.2,
+	}
+}
+
+// backoff returns the delay before the given retry, starting at 1.
+func (p *RetryPolicy) backoff(retry int) time.Duration {
+	delay := p.MinBackoff
+	for i := 1; i < retry && (p.MaxBackoff <= 0 || delay < p.MaxBackoff); i++ {
+		delay *= 2
+	}
+
+	if p.Jitter > 0 {
+		delay += time.Duration(float64(delay) * p.Jitter * (2*rand.Float64() - 1))
+	}
+
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+
+	return delay
+}
+
+// retryDelay reports how long to wait before retrying after err, and whether
+// the request should be retried at all.
+func (p *RetryPolicy) retryDelay(ctx context.Context, err error, retry int) (time.Duration, bool) {
+	if ctx.Err() != nil {
+		return 0, false
+	}
+
+	// Local files that can't be read won't become readable by retrying.
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		return 0, false
+	}
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		// Network errors and responses that couldn't be read.
+		return p.backoff(retry), true
+	}
+
+	switch {
+	case apiErr.RetryAfter > 0:
+		return time.Duration(apiErr.RetryAfter) * time.Second, true
+	case apiErr.Code == http.StatusTooManyRequests:
+		return p.backoff(retry), true
+	case apiErr.Code >= http.StatusInternalServerError:
+		return p.backoff(retry), true
+	default:
+		return 0, false
+	}
+}
+
+// withRetry calls do until it succeeds or bot.RetryPolicy gives up.
+func (bot *BotAPI) withRetry(ctx context.Context, endpoint string, do func() (*APIResponse, error)) (*APIResponse, error) {
+	policy := bot.RetryPolicy
+
+	for attempt := 1; ; attempt++ {
+		resp, err := do()
+		if err == nil || policy == nil || attempt >= policy.MaxAttempts {
+			return resp, err
+		}
+
+		delay, ok := policy.retryDelay(ctx, err, attempt)
+		if !ok {
+			return resp, err
+		}
+
+		// Don't wait for a retry that can't happen before the deadline.
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return resp, err
+		}
+
+		if bot.Debug {
+			log.Printf("Endpoint: %s, retrying in %s after error: %v\n", endpoint, delay, err)
+		}
+
+		if !sleepContext(ctx, delay) {
+			return resp, err
+		}
+	}
+}
+
+// pollRetryDelay returns how long GetUpdatesChan waits after its getUpdates
+// request failed the given number of times in a row.
+func (bot *BotAPI) pollRetryDelay(err error, failures int) time.Duration {
+	var apiErr *Error
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return time.Duration(apiErr.RetryAfter) * time.Second
+	}
+
+	if bot.RetryPolicy != nil {
+		return bot.RetryPolicy.backoff(failures)
+	}
+
+	return time.Second * 3
+}
+
+// sleepContext waits for the duration and reports whether it did so without
+// ctx being done first.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// rewindableFiles reports whether all files can be read again for another
+// attempt, and returns a function preparing them to be.
+//
+// FilePath and FileBytes create a new reader on every upload. A FileReader
+// can only be rewound if its Reader implements io.Seeker but not io.Closer,
+// as readers that can be closed are closed after the upload. Other
+// implementations of RequestFileData are not assumed to be reusable.
+func rewindableFiles(files []RequestFile) (func() error, bool) {
+	type seekTo struct {
+		seeker io.Seeker
+		offset int64
+	}
+
+	var seeks []seekTo
+
+	for _, file := range files {
+		if !file.Data.NeedsUpload() {
+			continue
+		}
+
+		switch data := file.Data.(type) {
+		case FilePath, FileBytes:
+		case FileReader:
+			seeker, ok := data.Reader.(io.Seeker)
+			if !ok {
+				return nil, false
+			}
+			if _, ok := data.Reader.(io.Closer); ok {
+				return nil, false
+			}
+
+			offset, err := seeker.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return nil, false
+			}
+
+			seeks = append(seeks, seekTo{seeker, offset})
+		default:
+			return nil, false
+		}
+	}
+
+	return func() error {
+		for _, s := range seeks {
+			if _, err := s.seeker.Seek(s.offset, io.SeekStart); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, true
+}
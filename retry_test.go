@@ -0,0 +1,184 @@
+package tgbotapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func fastRetryPolicy(attempts int) *RetryPolicy {
+	return &RetryPolicy{MaxAttempts: attempts, MinBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+}
+
+func TestRetryServerErrors(t *testing.T) {
+	var calls atomic.Int32
+
+	bot := newLocalBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte("<html>bad gateway</html>"))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+	})
+	bot.RetryPolicy = fastRetryPolicy(3)
+
+	if _, err := bot.Request(NewChatAction(int64(1), ChatActionTyping)); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("expected 3 attempts, got %d", calls.Load())
+	}
+}
+
+func TestRetryNeverRetriesClientErrors(t *testing.T) {
+	var calls atomic.Int32
+
+	bot := newLocalBot(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		_, _ = w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`))
+	})
+	bot.RetryPolicy = fastRetryPolicy(5)
+
+	_, err := bot.Request(NewChatAction(int64(1), ChatActionTyping))
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Code != 400 {
+		t.Fatalf("expected a 400 error, got %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected 1 attempt, got %d", calls.Load())
+	}
+}
+
+func TestRetryHonorsRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	var first time.Time
+
+	bot := newLocalBot(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			first = time.Now()
+			_, _ = w.Write([]byte(`{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 1","parameters":{"retry_after":1}}`))
+			return
+		}
+		if waited := time.Since(first); waited < time.Second {
+			t.Errorf("retried after %s, before retry_after", waited)
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+	})
+	bot.RetryPolicy = fastRetryPolicy(2)
+
+	if _, err := bot.Request(NewChatAction(int64(1), ChatActionTyping)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRetryUploadReopensFiles(t *testing.T) {
+	var calls atomic.Int32
+
+	bot := newLocalBot(t, func(w http.ResponseWriter, r *http.Request) {
+		file, _, err := r.FormFile("document")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		if string(data) != "content" {
+			t.Errorf("attempt %d uploaded %q", calls.Load()+1, data)
+		}
+
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"ok":false,"error_code":500,"description":"Internal Server Error"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":1,"date":0,"chat":{"id":1}}}`))
+	})
+	bot.RetryPolicy = fastRetryPolicy(2)
+
+	for _, data := range []RequestFileData{
+		FileBytes{Name: "doc.txt", Bytes: []byte("content")},
+		FileReader{Name: "doc.txt", Reader: strings.NewReader("content")},
+	} {
+		calls.Store(0)
+
+		if _, err := bot.Send(NewDocument(int64(1), data)); err != nil {
+			t.Fatal(err)
+		}
+		if calls.Load() != 2 {
+			t.Fatalf("expected 2 attempts, got %d", calls.Load())
+		}
+	}
+}
+
+func TestRetryUploadUnrewindableReader(t *testing.T) {
+	var calls atomic.Int32
+
+	bot := newLocalBot(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"ok":false,"error_code":500,"description":"Internal Server Error"}`))
+	})
+	bot.RetryPolicy = fastRetryPolicy(3)
+
+	reader := FileReader{Name: "doc.txt", Reader: io.MultiReader(strings.NewReader("content"))}
+	_, err := bot.Send(NewDocument(int64(1), reader))
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.Code != 500 {
+		t.Fatalf("expected a 500 error, got %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected 1 attempt, got %d", calls.Load())
+	}
+}
+
+func TestRetryUploadWaitsForPreviousAttempt(t *testing.T) {
+	// The server fails before reading the body, so the writer of an attempt
+	// may still be reading the file when the next one rewinds it.
+	bot := newLocalBot(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"ok":false,"error_code":500,"description":"Internal Server Error"}`))
+	})
+	bot.RetryPolicy = fastRetryPolicy(3)
+
+	var returned atomic.Bool
+	ctx := WithUploadProgress(context.Background(), func(written, total int64) {
+		if returned.Load() {
+			t.Error("progress reported after the request returned")
+		}
+	})
+
+	reader := FileReader{Name: "doc.bin", Reader: bytes.NewReader(make([]byte, 8<<20))}
+	_, err := bot.SendContext(ctx, NewDocument(int64(1), reader))
+	returned.Store(true)
+
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	time.Sleep(10 * time.Millisecond)
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := &RetryPolicy{MinBackoff: time.Second, MaxBackoff: 5 * time.Second}
+
+	for retry, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 10: 5 * time.Second} {
+		if got := policy.backoff(retry); got != want {
+			t.Errorf("retry %d: expected %s, got %s", retry, want, got)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := policy.backoff(2); got < time.Second || got > 3*time.Second {
+			t.Fatalf("jittered backoff %s out of range", got)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package tgbotapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Storage stores values by key, such as conversation state or file IDs.
+//
+// Implementations must be safe for concurrent use.
+type Storage interface {
+	// Get returns the value of key, or false if it isn't set or expired.
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	// Set sets the value of key. If ttl is not zero, the value expires after
+	// that time.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes key.
+	Delete(ctx context.Context, key string) error
+}
+
+type storageEntry struct {
+	Value   []byte    `json:"value"`
+	Expires time.Time `json:"expires,omitempty"`
+}
+
+func (e storageEntry) expired(now time.Time) bool {
+	return !e.Expires.IsZero() && !now.Before(e.Expires)
+}
+
+// MemoryStorage is a Storage keeping values in memory.
+type MemoryStorage struct {
+	mu        sync.Mutex
+	entries   map[string]storageEntry
+	lastSweep time.Time
+}
+
+// NewMemoryStorage creates an empty MemoryStorage.
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{entries: make(map[string]storageEntry)}
+}
+
+// Get returns the value of key.
+func (s *MemoryStorage) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || entry.expired(time.Now()) {
+		return nil, false, nil
+	}
+
+	return entry.Value, true, nil
+}
+
+// Set sets the value of key.
+func (s *MemoryStorage) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.set(key, value, ttl)
+
+	return nil
+}
+
+func (s *MemoryStorage) set(key string, value []byte, ttl time.Duration) {
+	now := time.Now()
+
+	entry := storageEntry{Value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		entry.Expires = now.Add(ttl)
+	}
+	s.entries[key] = entry
+
+	if now.Sub(s.lastSweep) > time.Minute {
+		s.sweep(now)
+	}
+}
+
+// Delete removes key.
+func (s *MemoryStorage) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+
+	return nil
+}
+
+// sweep removes expired values.
+func (s *MemoryStorage) sweep(now time.Time) {
+	s.lastSweep = now
+
+	for key, entry := range s.entries {
+		if entry.expired(now) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// FileStorage is a Storage keeping values in memory and saving them to a
+// JSON file after every change, so they survive restarts.
+//
+// The file is replaced atomically, so it is never left half written. It
+// should only be used by one FileStorage at a time.
+type FileStorage struct {
+	MemoryStorage
+
+	path string
+}
+
+// OpenFileStorage creates a FileStorage saving to path, loading the values
+// stored there if the file exists.
+func OpenFileStorage(path string) (*FileStorage, error) {
+	s := &FileStorage{
+		MemoryStorage: MemoryStorage{entries: make(map[string]storageEntry)},
+		path:          path,
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &s.entries); err != nil {
+		return nil, fmt.Errorf("reading storage %s: %w", path, err)
+	}
+
+	s.sweep(time.Now())
+
+	return s, nil
+}
+
+// Set sets the value of key and saves the file.
+func (s *FileStorage) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.set(key, value, ttl)
+
+	return s.save()
+}
+
+// Delete removes key and saves the file.
+func (s *FileStorage) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[key]; !ok {
+		return nil
+	}
+
+	delete(s.entries, key)
+
+	return s.save()
+}
+
+// save writes the values to the file.
+func (s *FileStorage) save() error {
+	data, err := json.Marshal(s.entries)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(s.path, data)
+}
+
+// writeFileAtomic writes data to a temporary file and moves it over path.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if err := writeAndClose(tmp, bytes.NewReader(data)); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return nil
+}
+
+// conversationRetention is how long timed out conversations are kept in a
+// Storage so that their timeout can still be reported.
+const conversationRetention = 24 * time.Hour
+
+// StorageConversationStore is a ConversationStore keeping conversations in
+// a Storage.
+type StorageConversationStore struct {
+	Storage Storage
+	// Prefix is prepended to the keys of conversations.
+	Prefix string
+}
+
+// NewStorageConversationStore creates a ConversationStore keeping
+// conversations in storage under keys starting with "conversation:".
+func NewStorageConversationStore(storage Storage) *StorageConversationStore {
+	return &StorageConversationStore{Storage: storage, Prefix: "conversation:"}
+}
+
+func (s *StorageConversationStore) key(key ConversationKey) string {
+	return fmt.Sprintf("%s%d:%d", s.Prefix, key.ChatID, key.UserID)
+}
+
+// Load returns the state of the conversation.
+func (s *StorageConversationStore) Load(ctx context.Context, key ConversationKey) (ConversationState, bool, error) {
+	data, ok, err := s.Storage.Get(ctx, s.key(key))
+	if err != nil || !ok {
+		return ConversationState{}, false, err
+	}
+
+	var state ConversationState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return ConversationState{}, false, err
+	}
+
+	return state, true, nil
+}
+
+// Save stores the state of the conversation.
+func (s *StorageConversationStore) Save(ctx context.Context, key ConversationKey, state ConversationState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	var ttl time.Duration
+	if !state.Expires.IsZero() {
+		ttl = time.Until(state.Expires) + conversationRetention
+	}
+
+	return s.Storage.Set(ctx, s.key(key), data, ttl)
+}
+
+// Delete forgets the conversation.
+func (s *StorageConversationStore) Delete(ctx context.Context, key ConversationKey) error {
+	return s.Storage.Delete(ctx, s.key(key))
+}
+
+// StorageFileIDCache is a FileIDCache keeping file IDs in a Storage.
+type StorageFileIDCache struct {
+	Storage Storage
+	// Prefix is prepended to the keys of files.
+	Prefix string
+	// TTL makes file IDs expire after this long, if not zero.
+	TTL time.Duration
+}
+
+// NewStorageFileIDCache creates a FileIDCache keeping file IDs in storage
+// under keys starting with "file_id:".
+func NewStorageFileIDCache(storage Storage) *StorageFileIDCache {
+	return &StorageFileIDCache{Storage: storage, Prefix: "file_id:"}
+}
+
+// Get returns the file ID stored for key. Errors are logged and treated as
+// a missing file ID.
+func (c *StorageFileIDCache) Get(key string) (string, bool) {
+	data, ok, err := c.Storage.Get(context.Background(), c.Prefix+key)
+	if err != nil {
+		log.Printf("Failed to get file ID: %v", err)
+		return "", false
+	}
+
+	return string(data), ok
+}
+
+// Set stores the file ID for key.
+func (c *StorageFileIDCache) Set(key, fileID string) {
+	if err := c.Storage.Set(context.Background(), c.Prefix+key, []byte(fileID), c.TTL); err != nil {
+		log.Printf("Failed to set file ID: %v", err)
+	}
+}
+
+// Delete forgets the file ID stored for key.
+func (c *StorageFileIDCache) Delete(key string) {
+	if err := c.Storage.Delete(context.Background(), c.Prefix+key); err != nil {
+		log.Printf("Failed to delete file ID: %v", err)
+	}
+}
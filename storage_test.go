@@ -0,0 +1,125 @@
+package tgbotapi
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMemoryStorage(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryStorage()
+
+	if err := s.Set(ctx, "a", []byte("1"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set(ctx, "b", []byte("2"), time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+
+	if value, ok, _ := s.Get(ctx, "a"); !ok || string(value) != "1" {
+		t.Fatalf("unexpected value %q", value)
+	}
+	if _, ok, _ := s.Get(ctx, "b"); ok {
+		t.Fatal("expired value was returned")
+	}
+
+	if err := s.Delete(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := s.Get(ctx, "a"); ok {
+		t.Fatal("deleted value was returned")
+	}
+}
+
+func TestFileStorage(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	s, err := OpenFileStorage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Set(ctx, "kept", []byte("1"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set(ctx, "expired", []byte("2"), time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set(ctx, "deleted", []byte("3"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete(ctx, "deleted"); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(5 * time.Millisecond)
+
+	s, err = OpenFileStorage(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if value, ok, _ := s.Get(ctx, "kept"); !ok || string(value) != "1" {
+		t.Fatalf("unexpected value %q", value)
+	}
+	for _, key := range []string{"expired", "deleted"} {
+		if _, ok, _ := s.Get(ctx, key); ok {
+			t.Fatalf("%s value was loaded", key)
+		}
+	}
+}
+
+func TestStorageConversationStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewStorageConversationStore(NewMemoryStorage())
+	key := ConversationKey{ChatID: -1, UserID: 2}
+
+	state := ConversationState{
+		Stack:   []ConversationFrame{{Conversation: "register", State: "name"}},
+		Data:    map[string]string{"name": "Ann"},
+		Expires: time.Now().Add(time.Minute).Round(0),
+	}
+
+	if err := store.Save(ctx, key, state); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, ok, err := store.Load(ctx, key)
+	if err != nil || !ok {
+		t.Fatalf("conversation was not loaded: %v", err)
+	}
+	if loaded.Stack[0] != state.Stack[0] || loaded.Data["name"] != "Ann" || !loaded.Expires.Equal(state.Expires) {
+		t.Fatalf("unexpected state %+v", loaded)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := store.Load(ctx, key); ok {
+		t.Fatal("deleted conversation was loaded")
+	}
+}
+
+func TestStorageFileIDCache(t *testing.T) {
+	storage := NewMemoryStorage()
+	cache := NewStorageFileIDCache(storage)
+
+	cache.Set("photo:sha256:1", "id")
+
+	if id, ok := cache.Get("photo:sha256:1"); !ok || id != "id" {
+		t.Fatalf("unexpected file ID %q", id)
+	}
+	if _, ok, _ := storage.Get(context.Background(), "file_id:photo:sha256:1"); !ok {
+		t.Fatal("file ID was not stored with the prefix")
+	}
+
+	cache.Delete("photo:sha256:1")
+	if _, ok := cache.Get("photo:sha256:1"); ok {
+		t.Fatal("deleted file ID was returned")
+	}
+}
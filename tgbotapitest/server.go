@@ -0,0 +1,535 @@
+// Package tgbotapitest provides a fake Telegram Bot API server for testing
+// bots without network access or a real token.
+//
+//	srv := tgbotapitest.NewServer()
+//	defer srv.Close()
+//
+//	bot, _ := srv.NewBot()
+//	bot.Send(tgbotapi.NewMessage(1, "hi"))
+//
+//	call, _ := srv.LastCall("sendMessage")
+//	// call.Params["text"] == "hi"
+package tgbotapitest
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/jhonroun/telegram-bot-api"
+)
+
+// DefaultToken is the token accepted by a new Server.
+const DefaultToken = "123456:test-token"
+
+// Call is a method call received by the Server.
+type Call struct {
+	Method string
+	Params tgbotapi.Params
+	Files  map[string]File
+}
+
+// File is a file uploaded with a Call.
+type File struct {
+	Name string
+	Data []byte
+}
+
+// Response is a scripted answer to a method call.
+type Response struct {
+	// Result is encoded as the result of a successful response.
+	Result any
+	// ErrorCode makes the response fail with Description if it is not zero.
+	// It is also used as the HTTP status.
+	ErrorCode   int
+	Description string
+	Parameters  *tgbotapi.ResponseParameters
+}
+
+// OK creates a successful Response with the result.
+func OK(result any) Response {
+	return Response{Result: result}
+}
+
+// Error creates a failed Response.
+func Error(code int, description string) Response {
+	return Response{ErrorCode: code, Description: description}
+}
+
+// TooManyRequests creates a flood control Response asking to wait for
+// retryAfter seconds.
+func TooManyRequests(retryAfter int) Response {
+	return Response{
+		ErrorCode:   http.StatusTooManyRequests,
+		Description: "Too Many Requests: retry after " + strconv.Itoa(retryAfter),
+		Parameters:  &tgbotapi.ResponseParameters{RetryAfter: retryAfter},
+	}
+}
+
+// ChatMigrated creates a Response failing because the group was upgraded to
+// the supergroup newChatID.
+func ChatMigrated(newChatID int64) Response {
+	return Response{
+		ErrorCode:   http.StatusBadRequest,
+		Description: "Bad Request: group chat was upgraded to a supergroup chat",
+		Parameters:  &tgbotapi.ResponseParameters{MigrateToChatID: newChatID},
+	}
+}
+
+// Server is a fake Bot API server recording method calls.
+//
+// Methods without a scripted response are answered with a plausible result:
+// getMe returns Bot, getUpdates returns the updates added with AddUpdates,
+// getFile returns the files added with AddFile, methods sending a message
+// return a message in the requested chat, forwardMessages and copyMessages
+// return one message ID per message and other methods return true.
+type Server struct {
+	*httptest.Server
+
+	// Token is the bot token requests must use.
+	Token string
+	// Bot is the user returned by getMe.
+	Bot tgbotapi.User
+
+	mu        sync.Mutex
+	calls     []Call
+	scripted  map[string][]Response
+	handlers  map[string]func(Call) Response
+	updates   []tgbotapi.Update
+	files     map[string]tgbotapi.File
+	fileData  map[string][]byte
+	nextID    int
+	messageID int
+	notify    chan struct{}
+}
+
+// NewServer starts a Server. It should be closed with Close.
+func NewServer() *Server {
+	s := &Server{
+		Token: DefaultToken,
+		Bot: tgbotapi.User{
+			ID:        123456,
+			IsBot:     true,
+			FirstName: "Test",
+			UserName:  "test_bot",
+		},
+		scripted: make(map[string][]Response),
+		handlers: make(map[string]func(Call) Response),
+		files:    make(map[string]tgbotapi.File),
+		fileData: make(map[string][]byte),
+		nextID:   1,
+		notify:   make(chan struct{}),
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+
+	return s
+}
+
+// Endpoint returns the API endpoint to pass to NewBotAPIWithAPIEndpoint.
+func (s *Server) Endpoint() string {
+	return s.URL + "/bot%s/%s"
+}
+
+// NewBot creates a BotAPI using the Server.
+func (s *Server) NewBot() (*tgbotapi.BotAPI, error) {
+	return tgbotapi.NewBotAPIWithClient(s.Token, s.Endpoint(), s.Client())
+}
+
+// Respond queues responses to the method. Each is used for one call, in
+// order, before falling back to the handler or the default response.
+func (s *Server) Respond(method string, responses ...Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.scripted[method] = append(s.scripted[method], responses...)
+}
+
+// Handle sets a handler answering every call to the method that has no
+// queued response.
+func (s *Server) Handle(method string, handler func(call Call) Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.handlers[method] = handler
+}
+
+// Calls returns the method calls received so far, excluding getMe and
+// getUpdates.
+func (s *Server) Calls() []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var calls []Call
+	for _, call := range s.calls {
+		if call.Method != "getMe" && call.Method != "getUpdates" {
+			calls = append(calls, call)
+		}
+	}
+
+	return calls
+}
+
+// CallsTo returns the calls received to the method.
+func (s *Server) CallsTo(method string) []Call {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var calls []Call
+	for _, call := range s.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+
+	return calls
+}
+
+// LastCall returns the last call received to the method.
+func (s *Server) LastCall(method string) (Call, bool) {
+	calls := s.CallsTo(method)
+	if len(calls) == 0 {
+		return Call{}, false
+	}
+
+	return calls[len(calls)-1], true
+}
+
+// Reset forgets the recorded calls and the scripted responses.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls = nil
+	s.scripted = make(map[string][]Response)
+	s.handlers = make(map[string]func(Call) Response)
+}
+
+// AddUpdates queues updates for getUpdates. Updates without an UpdateID are
+// numbered after the previous ones.
+func (s *Server) AddUpdates(updates ...tgbotapi.Update) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, update := range updates {
+		if update.UpdateID == 0 {
+			update.UpdateID = s.nextID
+		}
+		if update.UpdateID >= s.nextID {
+			s.nextID = update.UpdateID + 1
+		}
+
+		s.updates = append(s.updates, update)
+	}
+
+	// Wake up waiting getUpdates calls.
+	close(s.notify)
+	s.notify = make(chan struct{})
+}
+
+// AddFile makes a file available to getFile and for download.
+func (s *Server) AddFile(fileID string, data []byte) tgbotapi.File {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file := tgbotapi.File{
+		FileID:       fileID,
+		FileUniqueID: "unique-" + fileID,
+		FileSize:     len(data),
+		FilePath:     "files/" + fileID,
+	}
+
+	s.files[fileID] = file
+	s.fileData[file.FilePath] = data
+
+	return file
+}
+
+// DeliverWebhook sends an update to a webhook handler the way Telegram
+// does. A method written into the response is recorded as a call.
+func (s *Server) DeliverWebhook(handler http.Handler, secretToken string, update tgbotapi.Update) *httptest.ResponseRecorder {
+	body, err := json.Marshal(update)
+	if err != nil {
+		panic(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(string(body)))
+	req.Header.Set("Content-Type", "application/json")
+	if secretToken != "" {
+		req.Header.Set("X-Telegram-Bot-Api-Secret-Token", secretToken)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if strings.HasPrefix(rec.Header().Get("Content-Type"), "application/x-www-form-urlencoded") {
+		if values, err := url.ParseQuery(rec.Body.String()); err == nil && values.Get("method") != "" {
+			params := make(tgbotapi.Params)
+			for key := range values {
+				if key != "method" {
+					params[key] = values.Get(key)
+				}
+			}
+
+			s.mu.Lock()
+			s.calls = append(s.calls, Call{Method: values.Get("method"), Params: params})
+			s.mu.Unlock()
+		}
+	}
+
+	return rec
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if path, ok := strings.CutPrefix(r.URL.Path, "/file/bot"+s.Token+"/"); ok {
+		s.serveFile(w, path)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/bot")
+	token, method, ok := strings.Cut(path, "/")
+	if !ok || path == r.URL.Path {
+		writeResponse(w, Error(http.StatusNotFound, "Not Found"))
+		return
+	}
+
+	if token != s.Token {
+		writeResponse(w, Error(http.StatusUnauthorized, "Unauthorized"))
+		return
+	}
+
+	call, err := readCall(r, method)
+	if err != nil {
+		writeResponse(w, Error(http.StatusBadRequest, "Bad Request: "+err.Error()))
+		return
+	}
+
+	s.mu.Lock()
+	s.calls = append(s.calls, call)
+
+	if scripted := s.scripted[method]; len(scripted) > 0 {
+		s.scripted[method] = scripted[1:]
+		s.mu.Unlock()
+
+		writeResponse(w, scripted[0])
+		return
+	}
+
+	handler := s.handlers[method]
+	s.mu.Unlock()
+
+	if handler != nil {
+		writeResponse(w, handler(call))
+		return
+	}
+
+	writeResponse(w, s.defaultResponse(r.Context(), call))
+}
+
+func (s *Server) serveFile(w http.ResponseWriter, path string) {
+	s.mu.Lock()
+	data, ok := s.fileData[path]
+	s.mu.Unlock()
+
+	if !ok {
+		http.NotFound(w, nil)
+		return
+	}
+
+	_, _ = w.Write(data)
+}
+
+func (s *Server) defaultResponse(ctx context.Context, call Call) Response {
+	switch {
+	case call.Method == "getMe":
+		return OK(s.Bot)
+	case call.Method == "getUpdates":
+		return OK(s.getUpdates(ctx, call.Params))
+	case call.Method == "getFile":
+		s.mu.Lock()
+		file, ok := s.files[call.Params["file_id"]]
+		s.mu.Unlock()
+
+		if !ok {
+			return Error(http.StatusBadRequest, "Bad Request: wrong file_id or the file is temporarily unavailable")
+		}
+		return OK(file)
+	case call.Method == "copyMessage":
+		return OK(tgbotapi.MessageID{MessageID: s.newMessageID()})
+	case call.Method == "forwardMessages", call.Method == "copyMessages":
+		var ids []int
+		json.Unmarshal([]byte(call.Params["message_ids"]), &ids)
+
+		messageIDs := make([]tgbotapi.MessageID, len(ids))
+		for i := range ids {
+			messageIDs[i] = tgbotapi.MessageID{MessageID: s.newMessageID()}
+		}
+		return OK(messageIDs)
+	case call.Method == "forwardMessage",
+		strings.HasPrefix(call.Method, "send") && call.Method != "sendChatAction":
+		return OK(s.newMessage(call.Params))
+	default:
+		return OK(true)
+	}
+}
+
+// getUpdates returns the queued updates from the offset, waiting up to the
+// timeout for new ones.
+func (s *Server) getUpdates(ctx context.Context, params tgbotapi.Params) []tgbotapi.Update {
+	offset, _ := strconv.Atoi(params["offset"])
+	limit, _ := strconv.Atoi(params["limit"])
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+	timeout, _ := strconv.Atoi(params["timeout"])
+
+	deadline := time.NewTimer(time.Duration(timeout) * time.Second)
+	defer deadline.Stop()
+
+	for {
+		s.mu.Lock()
+
+		// Updates before the offset are confirmed and forgotten.
+		if offset > 0 {
+			i := 0
+			for i < len(s.updates) && s.updates[i].UpdateID < offset {
+				i++
+			}
+			s.updates = s.updates[i:]
+		}
+
+		updates := make([]tgbotapi.Update, 0, limit)
+		for _, update := range s.updates {
+			if len(updates) == limit {
+				break
+			}
+			updates = append(updates, update)
+		}
+
+		notify := s.notify
+		s.mu.Unlock()
+
+		if len(updates) > 0 || timeout <= 0 {
+			return updates
+		}
+
+		select {
+		case <-notify:
+		case <-deadline.C:
+			return updates
+		case <-ctx.Done():
+			return updates
+		}
+	}
+}
+
+func (s *Server) newMessageID() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messageID++
+
+	return s.messageID
+}
+
+func (s *Server) newMessage(params tgbotapi.Params) tgbotapi.Message {
+	chat := &tgbotapi.Chat{Type: "private"}
+	if id, err := strconv.ParseInt(params["chat_id"], 10, 64); err == nil {
+		chat.ID = id
+		if id < 0 {
+			chat.Type = "supergroup"
+		}
+	} else {
+		chat.Type = "channel"
+		chat.UserName = strings.TrimPrefix(params["chat_id"], "@")
+	}
+
+	bot := s.Bot
+
+	return tgbotapi.Message{
+		MessageID: s.newMessageID(),
+		From:      &bot,
+		Date:      int(time.Now().Unix()),
+		Chat:      chat,
+		Text:      params["text"],
+		Caption:   params["caption"],
+	}
+}
+
+// readCall decodes the parameters and files of a request.
+func readCall(r *http.Request, method string) (Call, error) {
+	call := Call{
+		Method: method,
+		Params: make(tgbotapi.Params),
+		Files:  make(map[string]File),
+	}
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			return call, err
+		}
+
+		for key, values := range r.MultipartForm.Value {
+			call.Params[key] = values[0]
+		}
+
+		for key, headers := range r.MultipartForm.File {
+			f, err := headers[0].Open()
+			if err != nil {
+				return call, err
+			}
+
+			data, err := io.ReadAll(f)
+			f.Close()
+			if err != nil {
+				return call, err
+			}
+
+			call.Files[key] = File{Name: headers[0].Filename, Data: data}
+		}
+
+		return call, nil
+	}
+
+	if err := r.ParseForm(); err != nil {
+		return call, err
+	}
+
+	for key := range r.PostForm {
+		call.Params[key] = r.PostForm.Get(key)
+	}
+
+	return call, nil
+}
+
+func writeResponse(w http.ResponseWriter, response Response) {
+	apiResp := tgbotapi.APIResponse{
+		Ok:          response.ErrorCode == 0,
+		ErrorCode:   response.ErrorCode,
+		Description: response.Description,
+		Parameters:  response.Parameters,
+	}
+
+	status := http.StatusOK
+	if response.ErrorCode != 0 {
+		status = response.ErrorCode
+	} else {
+		result, err := json.Marshal(response.Result)
+		if err != nil {
+			apiResp = tgbotapi.APIResponse{ErrorCode: http.StatusInternalServerError, Description: err.Error()}
+			status = http.StatusInternalServerError
+		}
+		apiResp.Result = result
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(apiResp)
+}
@@ -0,0 +1,175 @@
+package tgbotapitest_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/jhonroun/telegram-bot-api"
+	"github.com/jhonroun/telegram-bot-api/tgbotapitest"
+)
+
+func newBot(t *testing.T) (*tgbotapitest.Server, *tgbotapi.BotAPI) {
+	t.Helper()
+
+	srv := tgbotapitest.NewServer()
+	t.Cleanup(srv.Close)
+
+	bot, err := srv.NewBot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return srv, bot
+}
+
+func TestServerRecordsCalls(t *testing.T) {
+	srv, bot := newBot(t)
+
+	if bot.Self.UserName != "test_bot" {
+		t.Fatalf("unexpected bot %+v", bot.Self)
+	}
+
+	msg, err := bot.Send(tgbotapi.NewMessage(int64(42), "hi"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Chat.ID != 42 || msg.Text != "hi" {
+		t.Fatalf("unexpected message %+v", msg)
+	}
+
+	_, err = bot.Send(tgbotapi.NewDocument(int64(42), tgbotapi.FileBytes{Name: "doc.txt", Bytes: []byte("doc")}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := srv.Calls()
+	if len(calls) != 2 || calls[0].Method != "sendMessage" || calls[0].Params["text"] != "hi" {
+		t.Fatalf("unexpected calls %+v", calls)
+	}
+
+	call, ok := srv.LastCall("sendDocument")
+	if !ok || call.Params["chat_id"] != "42" {
+		t.Fatalf("unexpected call %+v", call)
+	}
+	if file := call.Files["document"]; file.Name != "doc.txt" || string(file.Data) != "doc" {
+		t.Fatalf("unexpected file %+v", file)
+	}
+}
+
+func TestServerScriptedResponses(t *testing.T) {
+	srv, bot := newBot(t)
+
+	srv.Respond("sendMessage",
+		tgbotapitest.Error(http.StatusForbidden, "Forbidden: bot was blocked by the user"),
+		tgbotapitest.TooManyRequests(1),
+	)
+
+	_, err := bot.Send(tgbotapi.NewMessage(int64(1), "hi"))
+	if !errors.Is(err, tgbotapi.ErrBotBlocked) {
+		t.Fatalf("expected ErrBotBlocked, got %v", err)
+	}
+
+	bot.RetryPolicy = tgbotapi.NewRetryPolicy(2)
+
+	start := time.Now()
+	if _, err := bot.Send(tgbotapi.NewMessage(int64(1), "hi")); err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(start) < time.Second {
+		t.Fatal("retry_after was not honored")
+	}
+
+	srv.Handle("getChat", func(call tgbotapitest.Call) tgbotapitest.Response {
+		return tgbotapitest.OK(tgbotapi.Chat{ID: 1, Type: "private", FirstName: call.Params["chat_id"]})
+	})
+
+	chat, err := bot.GetChat(tgbotapi.ChatInfoConfig{ChatConfig: tgbotapi.ChatConfig{ChatID: 7}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chat.FirstName != "7" {
+		t.Fatalf("unexpected chat %+v", chat)
+	}
+}
+
+func TestServerGetUpdates(t *testing.T) {
+	srv, bot := newBot(t)
+
+	srv.AddUpdates(tgbotapi.Update{Message: &tgbotapi.Message{Text: "one"}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updates := bot.GetUpdatesChanContext(ctx, tgbotapi.UpdateConfig{Timeout: 5})
+
+	first := <-updates
+	srv.AddUpdates(tgbotapi.Update{Message: &tgbotapi.Message{Text: "two"}})
+	second := <-updates
+
+	if first.UpdateID != 1 || first.Message.Text != "one" || second.UpdateID != 2 || second.Message.Text != "two" {
+		t.Fatalf("unexpected updates %+v %+v", first, second)
+	}
+
+	bot.StopReceivingUpdates()
+}
+
+func TestServerDeliverWebhook(t *testing.T) {
+	srv, bot := newBot(t)
+
+	dispatcher := tgbotapi.NewDispatcher(bot)
+	dispatcher.HandleCommand("start", func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		return tgbotapi.Reply(ctx, bot, tgbotapi.NewMessage(update.Message.Chat.ID, "welcome"))
+	})
+
+	webhook := tgbotapi.NewWebhookServer(bot, "secret")
+	webhook.Handler = dispatcher.Dispatch
+
+	update := tgbotapi.Update{
+		UpdateID: 1,
+		Message: &tgbotapi.Message{
+			Chat:     &tgbotapi.Chat{ID: 5, Type: "private"},
+			Text:     "/start",
+			Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Length: 6}},
+		},
+	}
+
+	if rec := srv.DeliverWebhook(webhook, "wrong", update); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rec.Code)
+	}
+	if rec := srv.DeliverWebhook(webhook, "secret", update); rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	call, ok := srv.LastCall("sendMessage")
+	if !ok || call.Params["chat_id"] != "5" || call.Params["text"] != "welcome" {
+		t.Fatalf("unexpected reply %+v", call)
+	}
+}
+
+func TestServerFiles(t *testing.T) {
+	srv, bot := newBot(t)
+
+	srv.AddFile("doc", []byte("content"))
+
+	r, file, err := bot.DownloadFile(context.Background(), "doc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "content" || file.FileSize != len(data) {
+		t.Fatalf("unexpected download %q of %+v", data, file)
+	}
+
+	if _, _, err := bot.DownloadFile(context.Background(), "missing"); !errors.Is(err, tgbotapi.ErrBadRequest) {
+		t.Fatalf("expected ErrBadRequest, got %v", err)
+	}
+}
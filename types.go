@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
 )
@@ -114,6 +115,20 @@ type Update struct {
 	//
 	// optional
 	ChatJoinRequest *ChatJoinRequest `json:"chat_join_request,omitempty"`
+	// MessageReaction is a reaction to a message changed by a user. The bot
+	// must be an administrator in the chat and must explicitly specify
+	// "message_reaction" in the list of allowed_updates to receive these
+	// updates.
+	//
+	// optional
+	MessageReaction *MessageReactionUpdated `json:"message_reaction,omitempty"`
+	// MessageReactionCount is the changed anonymous reactions to a message.
+	// The bot must be an administrator in the chat and must explicitly
+	// specify "message_reaction_count" in the list of allowed_updates to
+	// receive these updates.
+	//
+	// optional
+	MessageReactionCount *MessageReactionCountUpdated `json:"message_reaction_count,omitempty"`
 }
 
 // SentFrom returns the user who sent an update. Can be nil, if Telegram did not provide information
@@ -134,6 +149,8 @@ func (u *Update) SentFrom() *User {
 		return u.ShippingQuery.From
 	case u.PreCheckoutQuery != nil:
 		return u.PreCheckoutQuery.From
+	case u.MessageReaction != nil:
+		return u.MessageReaction.User
 	default:
 		return nil
 	}
@@ -158,8 +175,70 @@ func (u *Update) FromChat() *Chat {
 		return u.ChannelPost.Chat
 	case u.EditedChannelPost != nil:
 		return u.EditedChannelPost.Chat
-	case u.CallbackQuery != nil:
+	case u.CallbackQuery != nil && u.CallbackQuery.Message != nil:
 		return u.CallbackQuery.Message.Chat
+	case u.MessageReaction != nil:
+		return &u.MessageReaction.Chat
+	case u.MessageReactionCount != nil:
+		return &u.MessageReactionCount.Chat
+	default:
+		return nil
+	}
+}
+
+// UpdateType returns the type of the update as one of the UpdateType
+// constants, or an empty string if the update kind is unknown.
+func (u *Update) UpdateType() string {
+	switch {
+	case u.Message != nil:
+		return UpdateTypeMessage
+	case u.EditedMessage != nil:
+		return UpdateTypeEditedMessage
+	case u.ChannelPost != nil:
+		return UpdateTypeChannelPost
+	case u.EditedChannelPost != nil:
+		return UpdateTypeEditedChannelPost
+	case u.InlineQuery != nil:
+		return UpdateTypeInlineQuery
+	case u.ChosenInlineResult != nil:
+		return UpdateTypeChosenInlineResult
+	case u.CallbackQuery != nil:
+		return UpdateTypeCallbackQuery
+	case u.ShippingQuery != nil:
+		return UpdateTypeShippingQuery
+	case u.PreCheckoutQuery != nil:
+		return UpdateTypePreCheckoutQuery
+	case u.Poll != nil:
+		return UpdateTypePoll
+	case u.PollAnswer != nil:
+		return UpdateTypePollAnswer
+	case u.MyChatMember != nil:
+		return UpdateTypeMyChatMember
+	case u.ChatMember != nil:
+		return UpdateTypeChatMember
+	case u.ChatJoinRequest != nil:
+		return UpdateTypeChatJoinRequest
+	case u.MessageReaction != nil:
+		return UpdateTypeMessageReaction
+	case u.MessageReactionCount != nil:
+		return UpdateTypeMessageReactionCount
+	default:
+		return ""
+	}
+}
+
+// EffectiveMessage returns the message carried by the update, whether it is
+// a new or edited message or channel post.
+func (u *Update) EffectiveMessage() *Message {
+	switch {
+	case u.Message != nil:
+		return u.Message
+	case u.EditedMessage != nil:
+		return u.EditedMessage
+	case u.ChannelPost != nil:
+		return u.ChannelPost
+	case u.EditedChannelPost != nil:
+		return u.EditedChannelPost
 	default:
 		return nil
 	}
@@ -370,6 +449,11 @@ type Chat struct {
 	//
 	// optional
 	HasAgressiveAntiSpamEnabled bool `json:"has_aggressive_anti_spam_enabled,omitempty"`
+	// AvailableReactions is the list of reactions allowed in the chat. If
+	// omitted, all emoji reactions are allowed. Returned only in getChat.
+	//
+	// optional
+	AvailableReactions []ReactionType `json:"available_reactions,omitempty"` // 7.0
 }
 
 // IsPrivate returns if the Chat is a private conversation.
@@ -737,6 +821,21 @@ type Message struct {
 	//
 	// optional
 	ChatShared *ChatShared `json:"chat_shared,omitempty"`
+	// ExternalReply is information about the message being replied to, which
+	// may come from another chat or forum topic.
+	//
+	// optional
+	ExternalReply *ExternalReplyInfo `json:"external_reply,omitempty"`
+	// Quote is for replies that quote part of the original message, the
+	// quoted part of the message.
+	//
+	// optional
+	Quote *TextQuote `json:"quote,omitempty"`
+	// LinkPreviewOptions used for link preview generation for the message,
+	// if it is a text message and link preview options were changed.
+	//
+	// optional
+	LinkPreviewOptions *LinkPreviewOptions `json:"link_preview_options,omitempty"`
 }
 
 // Time converts the message timestamp into a Time.
@@ -813,6 +912,218 @@ type MessageID struct {
 	MessageID int `json:"message_id"`
 }
 
+// MessageOrigin describes the origin of a message.
+//
+// It contains the fields for all types of origins, different types only use
+// specific fields.
+type MessageOrigin struct {
+	// Type of the message origin, one of “user”, “hidden_user”, “chat” or
+	// “channel”.
+	Type string `json:"type"`
+	// Date the message was sent originally in Unix time.
+	Date int `json:"date"`
+	// SenderUser that sent the message originally, for the “user” type.
+	//
+	// optional
+	SenderUser *User `json:"sender_user,omitempty"`
+	// SenderUserName of the user that sent the message originally, for the
+	// “hidden_user” type.
+	//
+	// optional
+	SenderUserName string `json:"sender_user_name,omitempty"`
+	// SenderChat that sent the message originally, for the “chat” type.
+	//
+	// optional
+	SenderChat *Chat `json:"sender_chat,omitempty"`
+	// Chat is the channel the message was originally sent to, for the
+	// “channel” type.
+	//
+	// optional
+	Chat *Chat `json:"chat,omitempty"`
+	// MessageID is the unique message identifier inside the channel, for the
+	// “channel” type.
+	//
+	// optional
+	MessageID int `json:"message_id,omitempty"`
+	// AuthorSignature is the signature of the original post author.
+	//
+	// optional
+	AuthorSignature string `json:"author_signature,omitempty"`
+}
+
+// ExternalReplyInfo contains information about a message that is being
+// replied to, which may come from another chat or forum topic.
+type ExternalReplyInfo struct {
+	// Origin of the message replied to.
+	Origin MessageOrigin `json:"origin"`
+	// Chat the original message belongs to, if it is a supergroup or a
+	// channel.
+	//
+	// optional
+	Chat *Chat `json:"chat,omitempty"`
+	// MessageID is the unique message identifier inside the original chat,
+	// if it is a supergroup or a channel.
+	//
+	// optional
+	MessageID int `json:"message_id,omitempty"`
+	// LinkPreviewOptions used for link preview generation for the original
+	// message, if it is a text message.
+	//
+	// optional
+	LinkPreviewOptions *LinkPreviewOptions `json:"link_preview_options,omitempty"`
+	// Animation if the message is an animation.
+	//
+	// optional
+	Animation *Animation `json:"animation,omitempty"`
+	// Audio if the message is an audio file.
+	//
+	// optional
+	Audio *Audio `json:"audio,omitempty"`
+	// Document if the message is a general file.
+	//
+	// optional
+	Document *Document `json:"document,omitempty"`
+	// Photo if the message is a photo, the available sizes of the photo.
+	//
+	// optional
+	Photo []PhotoSize `json:"photo,omitempty"`
+	// Sticker if the message is a sticker.
+	//
+	// optional
+	Sticker *Sticker `json:"sticker,omitempty"`
+	// Video if the message is a video.
+	//
+	// optional
+	Video *Video `json:"video,omitempty"`
+	// VideoNote if the message is a video note.
+	//
+	// optional
+	VideoNote *VideoNote `json:"video_note,omitempty"`
+	// Voice if the message is a voice message.
+	//
+	// optional
+	Voice *Voice `json:"voice,omitempty"`
+	// HasMediaSpoiler is true if the message media is covered by a spoiler
+	// animation.
+	//
+	// optional
+	HasMediaSpoiler bool `json:"has_media_spoiler,omitempty"`
+	// Contact if the message is a shared contact.
+	//
+	// optional
+	Contact *Contact `json:"contact,omitempty"`
+	// Dice if the message is a dice with a random value.
+	//
+	// optional
+	Dice *Dice `json:"dice,omitempty"`
+	// Game if the message is a game.
+	//
+	// optional
+	Game *Game `json:"game,omitempty"`
+	// Invoice if the message is an invoice for a payment.
+	//
+	// optional
+	Invoice *Invoice `json:"invoice,omitempty"`
+	// Location if the message is a shared location.
+	//
+	// optional
+	Location *Location `json:"location,omitempty"`
+	// Poll if the message is a native poll.
+	//
+	// optional
+	Poll *Poll `json:"poll,omitempty"`
+	// Venue if the message is a venue.
+	//
+	// optional
+	Venue *Venue `json:"venue,omitempty"`
+}
+
+// TextQuote contains information about the quoted part of a message that
+// is replied to by the given message.
+type TextQuote struct {
+	// Text of the quoted part of the message.
+	Text string `json:"text"`
+	// Entities are the special entities that appear in the quote. Only bold,
+	// italic, underline, strikethrough, spoiler, and custom_emoji entities
+	// are kept.
+	//
+	// optional
+	Entities []MessageEntity `json:"entities,omitempty"`
+	// Position of the quote in the original message in UTF-16 code units.
+	Position int `json:"position"`
+	// IsManual is true if the quote was chosen manually by the message
+	// sender, and false if it was added automatically by the server.
+	//
+	// optional
+	IsManual bool `json:"is_manual,omitempty"`
+}
+
+// ReplyParameters describes the message to reply to.
+type ReplyParameters struct {
+	// MessageID of the message to reply to in the current chat, or in the
+	// chat ChatID if it is set.
+	MessageID int `json:"message_id"`
+	// ChatID of the chat the message to reply to belongs to, if it is not
+	// the current chat.
+	//
+	// optional
+	ChatID int64 `json:"chat_id,omitempty"`
+	// AllowSendingWithoutReply sends the message even if the message to
+	// reply to is not found. Can be used only for replies in the same chat
+	// and forum topic.
+	//
+	// optional
+	AllowSendingWithoutReply bool `json:"allow_sending_without_reply,omitempty"`
+	// Quote is the quoted part of the message to reply to, 0-1024 characters
+	// after entities parsing. It must be an exact substring of the message
+	// to reply to.
+	//
+	// optional
+	Quote string `json:"quote,omitempty"`
+	// QuoteParseMode is the mode for parsing entities in the quote.
+	//
+	// optional
+	QuoteParseMode string `json:"quote_parse_mode,omitempty"`
+	// QuoteEntities are the special entities that appear in the quote, which
+	// can be specified instead of QuoteParseMode.
+	//
+	// optional
+	QuoteEntities []MessageEntity `json:"quote_entities,omitempty"`
+	// QuotePosition is the position of the quote in the original message in
+	// UTF-16 code units.
+	//
+	// optional
+	QuotePosition int `json:"quote_position,omitempty"`
+}
+
+// LinkPreviewOptions describes the options used for link preview
+// generation.
+type LinkPreviewOptions struct {
+	// IsDisabled disables the link preview.
+	//
+	// optional
+	IsDisabled bool `json:"is_disabled,omitempty"`
+	// URL to use for the link preview. If empty, the first URL found in the
+	// message text is used.
+	//
+	// optional
+	URL string `json:"url,omitempty"`
+	// PreferSmallMedia shrinks the media in the link preview, if it is
+	// supported.
+	//
+	// optional
+	PreferSmallMedia bool `json:"prefer_small_media,omitempty"`
+	// PreferLargeMedia enlarges the media in the link preview, if it is
+	// supported.
+	//
+	// optional
+	PreferLargeMedia bool `json:"prefer_large_media,omitempty"`
+	// ShowAboveText shows the link preview above the message text.
+	//
+	// optional
+	ShowAboveText bool `json:"show_above_text,omitempty"`
+}
+
 // MessageEntity represents one special entity in a text message.
 type MessageEntity struct {
 	// Type of the entity.
@@ -2019,6 +2330,93 @@ type ChatJoinRequest struct {
 	UserChatID int64 `json:"user_chat_id,omitempty"`
 }
 
+// ReactionType describes the type of a reaction.
+//
+// It contains the fields for all types of reactions, different types only
+// use specific (or no) fields.
+type ReactionType struct {
+	// Type of the reaction, one of ReactionTypeEmoji, ReactionTypeCustomEmoji
+	// or ReactionTypePaid.
+	Type string `json:"type"`
+	// Emoji of an emoji reaction.
+	//
+	// optional
+	Emoji string `json:"emoji,omitempty"`
+	// CustomEmojiID of a custom emoji reaction.
+	//
+	// optional
+	CustomEmojiID string `json:"custom_emoji_id,omitempty"`
+}
+
+// ReactionCount represents a reaction added to a message along with the
+// number of times it was added.
+type ReactionCount struct {
+	// Type of the reaction.
+	Type ReactionType `json:"type"`
+	// TotalCount is the number of times the reaction was added.
+	TotalCount int `json:"total_count"`
+}
+
+// MessageReactionUpdated represents a change of a reaction on a message
+// performed by a user.
+type MessageReactionUpdated struct {
+	// Chat containing the message the user reacted to.
+	Chat Chat `json:"chat"`
+	// MessageID is the unique identifier of the message inside the chat.
+	MessageID int `json:"message_id"`
+	// User that changed the reaction, if the user isn't anonymous.
+	//
+	// optional
+	User *User `json:"user,omitempty"`
+	// ActorChat is the chat on behalf of which the reaction was changed, if
+	// the user is anonymous.
+	//
+	// optional
+	ActorChat *Chat `json:"actor_chat,omitempty"`
+	// Date of the change in Unix time.
+	Date int `json:"date"`
+	// OldReaction is the previous list of reactions set by the user.
+	OldReaction []ReactionType `json:"old_reaction"`
+	// NewReaction is the new list of reactions set by the user.
+	NewReaction []ReactionType `json:"new_reaction"`
+}
+
+// Added returns the reactions in NewReaction that were not in OldReaction.
+func (r *MessageReactionUpdated) Added() []ReactionType {
+	return reactionsDiff(r.NewReaction, r.OldReaction)
+}
+
+// Removed returns the reactions in OldReaction that are not in NewReaction.
+func (r *MessageReactionUpdated) Removed() []ReactionType {
+	return reactionsDiff(r.OldReaction, r.NewReaction)
+}
+
+// reactionsDiff returns the reactions in a that are not in b.
+func reactionsDiff(a, b []ReactionType) []ReactionType {
+	var diff []ReactionType
+
+	for _, reaction := range a {
+		if !slices.Contains(b, reaction) {
+			diff = append(diff, reaction)
+		}
+	}
+
+	return diff
+}
+
+// MessageReactionCountUpdated represents changes to the anonymous reactions
+// on a message.
+type MessageReactionCountUpdated struct {
+	// Chat containing the message.
+	Chat Chat `json:"chat"`
+	// MessageID is the unique identifier of the message inside the chat.
+	MessageID int `json:"message_id"`
+	// Date of the change in Unix time.
+	Date int `json:"date"`
+	// Reactions is the list of reactions present on the message.
+	Reactions []ReactionCount `json:"reactions"`
+}
+
 // ChatPermissions describes actions that a non-administrator user is
 // allowed to take in a chat. All fields are optional.
 type ChatPermissions struct {
@@ -2073,6 +2471,23 @@ type BotCommand struct {
 	Description string `json:"description"`
 }
 
+// BotName represents the bot's name.
+type BotName struct {
+	Name string `json:"name"`
+}
+
+// BotDescription represents the bot's description, shown in the chat with
+// the bot if it is empty.
+type BotDescription struct {
+	Description string `json:"description"`
+}
+
+// BotShortDescription represents the bot's short description, shown on its
+// profile page and sent together with links to it.
+type BotShortDescription struct {
+	ShortDescription string `json:"short_description"`
+}
+
 // BotCommandScope represents the scope to which bot commands are applied.
 //
 // It contains the fields for all types of scopes, different types only support
@@ -2242,6 +2657,72 @@ type InputMediaDocument struct {
 	DisableContentTypeDetection bool `json:"disable_content_type_detection,omitempty"`
 }
 
+// InputMedia is media to send in a media group or to replace the media of a
+// message with. It is implemented by InputMediaPhoto, InputMediaVideo,
+// InputMediaAnimation, InputMediaAudio and InputMediaDocument.
+type InputMedia interface {
+	// mediaFiles returns the media file and the thumbnail, if any.
+	mediaFiles() (media, thumb RequestFileData)
+	// withFiles returns a copy of the media using other files.
+	withFiles(media, thumb RequestFileData) InputMedia
+	mediaType() string
+}
+
+func (m InputMediaPhoto) mediaFiles() (RequestFileData, RequestFileData) {
+	return m.Media, nil
+}
+
+func (m InputMediaPhoto) withFiles(media, thumb RequestFileData) InputMedia {
+	m.Media = media
+	return m
+}
+
+func (m InputMediaPhoto) mediaType() string { return "photo" }
+
+func (m InputMediaVideo) mediaFiles() (RequestFileData, RequestFileData) {
+	return m.Media, m.Thumb
+}
+
+func (m InputMediaVideo) withFiles(media, thumb RequestFileData) InputMedia {
+	m.Media, m.Thumb = media, thumb
+	return m
+}
+
+func (m InputMediaVideo) mediaType() string { return "video" }
+
+func (m InputMediaAnimation) mediaFiles() (RequestFileData, RequestFileData) {
+	return m.Media, m.Thumb
+}
+
+func (m InputMediaAnimation) withFiles(media, thumb RequestFileData) InputMedia {
+	m.Media, m.Thumb = media, thumb
+	return m
+}
+
+func (m InputMediaAnimation) mediaType() string { return "animation" }
+
+func (m InputMediaAudio) mediaFiles() (RequestFileData, RequestFileData) {
+	return m.Media, m.Thumb
+}
+
+func (m InputMediaAudio) withFiles(media, thumb RequestFileData) InputMedia {
+	m.Media, m.Thumb = media, thumb
+	return m
+}
+
+func (m InputMediaAudio) mediaType() string { return "audio" }
+
+func (m InputMediaDocument) mediaFiles() (RequestFileData, RequestFileData) {
+	return m.Media, m.Thumb
+}
+
+func (m InputMediaDocument) withFiles(media, thumb RequestFileData) InputMedia {
+	m.Media, m.Thumb = media, thumb
+	return m
+}
+
+func (m InputMediaDocument) mediaType() string { return "document" }
+
 // Sticker represents a sticker.
 type Sticker struct {
 	// FileID is an identifier for this file, which can be used to download or
@@ -2266,7 +2747,7 @@ type Sticker struct {
 	// Thumbnail sticker thumbnail in the .WEBP or .JPG format
 	//
 	// optional
-	Thumbnail *PhotoSize `json:"thumb,omitempty"`
+	Thumbnail *PhotoSize `json:"thumbnail,omitempty"`
 	// Emoji associated with the sticker
 	//
 	// optional
@@ -2317,8 +2798,11 @@ type StickerSet struct {
 	IsVideo bool `json:"is_video"`
 	// Stickers list of all set stickers
 	Stickers []Sticker `json:"stickers"`
-	// Thumb is the sticker set thumbnail in the .WEBP or .TGS format
-	Thumbnail *PhotoSize `json:"thumb"`
+	// Thumbnail is the sticker set thumbnail in the .WEBP, .TGS, or .WEBM
+	// format
+	//
+	// optional
+	Thumbnail *PhotoSize `json:"thumbnail,omitempty"`
 }
 
 // MaskPosition describes the position on faces where a mask should be placed
@@ -2339,6 +2823,29 @@ type MaskPosition struct {
 	Scale float64 `json:"scale"`
 }
 
+// InputSticker describes a sticker to be added to a sticker set.
+type InputSticker struct {
+	// Sticker is the file of the sticker. Pass a file_id or an HTTP URL to use
+	// a file already on the Internet, or upload a new one. Animated and video
+	// stickers can't be passed by URL.
+	Sticker RequestFileData `json:"sticker"`
+	// Format of the sticker, one of StickerFormatStatic,
+	// StickerFormatAnimated or StickerFormatVideo.
+	Format StickerFormat `json:"format"`
+	// EmojiList of 1-20 emoji associated with the sticker.
+	EmojiList []string `json:"emoji_list"`
+	// MaskPosition is the position where the mask should be placed on faces,
+	// for mask stickers only.
+	//
+	// optional
+	MaskPosition *MaskPosition `json:"mask_position,omitempty"`
+	// Keywords are 0-20 search keywords for the sticker, with a total length
+	// of up to 64 characters, for regular and custom emoji stickers only.
+	//
+	// optional
+	Keywords []string `json:"keywords,omitempty"`
+}
+
 // Game represents a game. Use BotFather to create and edit games, their short
 // names will act as unique identifiers.
 type Game struct {
@@ -3286,6 +3793,10 @@ type InputTextMessageContent struct {
 	//
 	// optional
 	DisableWebPagePreview bool `json:"disable_web_page_preview,omitempty"`
+	// LinkPreviewOptions for the link preview generation of the message.
+	//
+	// optional
+	LinkPreviewOptions *LinkPreviewOptions `json:"link_preview_options,omitempty"`
 }
 
 // InputLocationMessageContent contains a location for displaying
@@ -3829,6 +4340,21 @@ const (
 // String implements fmt.Stringer for StickerType.
 func (s StickerType) String() string { return string(s) }
 
+// StickerFormat describes the format of a sticker file.
+type StickerFormat string
+
+const (
+	// StickerFormatStatic is a .WEBP or .PNG image.
+	StickerFormatStatic StickerFormat = "static"
+	// StickerFormatAnimated is a .TGS animation.
+	StickerFormatAnimated StickerFormat = "animated"
+	// StickerFormatVideo is a .WEBM video.
+	StickerFormatVideo StickerFormat = "video"
+)
+
+// String implements fmt.Stringer for StickerFormat.
+func (s StickerFormat) String() string { return string(s) }
+
 // ForumTopic describes a topic created in a forum supergroup.
 type ForumTopic struct {
 	// MessageThreadID is the unique identifier of the forum topic thread
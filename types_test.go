@@ -367,7 +367,8 @@ var (
 	_ Fileable = (*AddStickerConfig)(nil)
 	_ Fileable = (*MediaGroupConfig)(nil)
 	_ Fileable = (*WebhookConfig)(nil)
-	_ Fileable = (*SetStickerSetThumbConfig)(nil)
+	_ Fileable = (*SetStickerSetThumbnailConfig)(nil)
+	_ Fileable = (*ReplaceStickerInSetConfig)(nil)
 )
 
 // Ensure all RequestFileData types are correct.
@@ -0,0 +1,396 @@
+package tgbotapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultWebhookMaxBodySize is the default limit on the size of an update
+// received by a WebhookServer.
+const DefaultWebhookMaxBodySize = 1 << 20
+
+// secretTokenHeader is the header Telegram puts the secret_token given to
+// setWebhook in.
+const secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// WebhookServer receives updates sent to a webhook.
+//
+// It implements http.Handler, so it can be mounted on any mux, or it can run
+// its own server with ListenAndServe or ListenAndServeTLS. Updates are
+// acknowledged as soon as they are queued and read from Updates, unless a
+// Handler is set.
+type WebhookServer struct {
+	Bot *BotAPI
+
+	// SecretToken is the secret_token given to setWebhook. Requests without
+	// a matching X-Telegram-Bot-Api-Secret-Token header are rejected. If
+	// empty, requests are not checked.
+	SecretToken string
+	// MaxBodySize limits the size of request bodies. Defaults to
+	// DefaultWebhookMaxBodySize.
+	MaxBodySize int64
+
+	// Handler, if set, is called with each update while the request waits
+	// instead of queuing it, so that the first method passed to Reply is
+	// written into the response. A Dispatcher can be used by passing its
+	// Dispatch method. It must return once its context is done.
+	Handler func(ctx context.Context, update Update) error
+	// ErrorHandler is called with errors returned by Handler. If nil, errors
+	// are logged.
+	ErrorHandler func(update Update, err error)
+
+	updates  chan Update
+	closing  chan struct{}
+	mu       sync.RWMutex
+	closed   bool
+	inFlight sync.WaitGroup
+	server   *http.Server
+}
+
+// NewWebhookServer creates a WebhookServer for the bot checking secretToken.
+// Up to bot.Buffer updates are queued before requests have to wait.
+func NewWebhookServer(bot *BotAPI, secretToken string) *WebhookServer {
+	return &WebhookServer{
+		Bot:         bot,
+		SecretToken: secretToken,
+		MaxBodySize: DefaultWebhookMaxBodySize,
+		updates:     make(chan Update, bot.Buffer),
+		closing:     make(chan struct{}),
+	}
+}
+
+// Updates returns the channel updates are sent to. It is closed by Shutdown
+// once every request being handled has queued its update.
+func (s *WebhookServer) Updates() UpdatesChannel {
+	return s.updates
+}
+
+// ServeHTTP handles a request from Telegram.
+//
+// When the queue is full the request waits for room. If the server shuts
+// down or the request is cancelled meanwhile, it fails with a 503 status so
+// Telegram sends the update again later. Updates the bot's UpdateIDStore
+// has seen before are acknowledged and dropped.
+func (s *WebhookServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.enter() {
+		writeWebhookError(w, http.StatusServiceUnavailable, errors.New("server is shutting down"))
+		return
+	}
+	defer s.inFlight.Done()
+
+	update, status, err := s.readUpdate(w, r)
+	if err != nil {
+		if s.Bot.Debug {
+			log.Printf("Webhook request rejected: %v\n", err)
+		}
+
+		writeWebhookError(w, status, err)
+		return
+	}
+
+	if s.Bot.isDuplicate(r.Context(), update) {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if s.Handler != nil {
+		s.handle(w, r, update)
+		return
+	}
+
+	select {
+	case s.updates <- update:
+		w.WriteHeader(http.StatusOK)
+	case <-s.closing:
+		s.Bot.updateDropped(update)
+		writeWebhookError(w, http.StatusServiceUnavailable, errors.New("server is shutting down"))
+	case <-r.Context().Done():
+		s.Bot.updateDropped(update)
+		writeWebhookError(w, http.StatusServiceUnavailable, r.Context().Err())
+	}
+}
+
+// handle calls the Handler with the update and answers the request with its
+// reply, if any. The context of the Handler is cancelled when Shutdown gives
+// up waiting for it.
+func (s *WebhookServer) handle(w http.ResponseWriter, r *http.Request, update Update) {
+	handlerCtx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	go func() {
+		select {
+		case <-s.closing:
+			cancel()
+		case <-handlerCtx.Done():
+		}
+	}()
+
+	reply := &webhookReply{}
+	ctx := context.WithValue(handlerCtx, webhookReplyKey{}, reply)
+
+	if err := s.Handler(ctx, update); err != nil {
+		s.Bot.updateDropped(update)
+
+		if s.isClosing() {
+			// Telegram sends the update again.
+			writeWebhookError(w, http.StatusServiceUnavailable, err)
+			return
+		}
+
+		if s.ErrorHandler != nil {
+			s.ErrorHandler(update, err)
+		} else {
+			log.Printf("Failed to handle update %d: %v", update.UpdateID, err)
+		}
+	} else if err := s.Bot.updateHandled(ctx, update); err != nil {
+		log.Printf("Failed to record update %d as handled: %v", update.UpdateID, err)
+	}
+
+	c := reply.finish()
+	if c == nil {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if err := WriteToHTTPResponse(w, c); err != nil && s.Bot.Debug {
+		log.Printf("Failed to write webhook reply: %v\n", err)
+	}
+}
+
+// isClosing reports whether Shutdown gave up waiting for the requests being
+// handled.
+func (s *WebhookServer) isClosing() bool {
+	select {
+	case <-s.closing:
+		return true
+	default:
+		return false
+	}
+}
+
+// enter registers a request unless the server is shutting down.
+func (s *WebhookServer) enter() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return false
+	}
+
+	s.inFlight.Add(1)
+
+	return true
+}
+
+// readUpdate validates a request and decodes its update, returning the
+// status to respond with if it can't.
+func (s *WebhookServer) readUpdate(w http.ResponseWriter, r *http.Request) (Update, int, error) {
+	if r.Method != http.MethodPost {
+		return Update{}, http.StatusMethodNotAllowed, errors.New("wrong HTTP method required POST")
+	}
+
+	if s.SecretToken != "" {
+		token := r.Header.Get(secretTokenHeader)
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.SecretToken)) != 1 {
+			return Update{}, http.StatusUnauthorized, errors.New("wrong secret token")
+		}
+	}
+
+	maxBodySize := s.MaxBodySize
+	if maxBodySize <= 0 {
+		maxBodySize = DefaultWebhookMaxBodySize
+	}
+
+	var update Update
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&update); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return Update{}, http.StatusRequestEntityTooLarge, err
+		}
+
+		return Update{}, http.StatusBadRequest, err
+	}
+
+	return update, http.StatusOK, nil
+}
+
+// ListenAndServe starts an HTTP server on addr handling webhook requests,
+// for use behind a proxy terminating TLS. It blocks until the server fails
+// or Shutdown is called, in which case it returns http.ErrServerClosed.
+func (s *WebhookServer) ListenAndServe(addr string) error {
+	return s.serve(addr, func(srv *http.Server, ln net.Listener) error {
+		return srv.Serve(ln)
+	})
+}
+
+// ListenAndServeTLS is the same as ListenAndServe except it serves HTTPS
+// using the certificate and key files.
+//
+// A self-signed certificate must also be uploaded to Telegram, for example
+// with NewWebhookWithCert(link, FilePath(certFile)).
+func (s *WebhookServer) ListenAndServeTLS(addr, certFile, keyFile string) error {
+	return s.serve(addr, func(srv *http.Server, ln net.Listener) error {
+		return srv.ServeTLS(ln, certFile, keyFile)
+	})
+}
+
+func (s *WebhookServer) serve(addr string, serve func(*http.Server, net.Listener) error) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if s.closed || s.server != nil {
+		s.mu.Unlock()
+		ln.Close()
+
+		if s.closed {
+			return http.ErrServerClosed
+		}
+		return errors.New("webhook server is already running")
+	}
+
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	s.server = srv
+	s.mu.Unlock()
+
+	return serve(srv, ln)
+}
+
+// Shutdown stops accepting requests, waits for the requests being handled
+// and closes the Updates channel. Updates already queued can still be read
+// from it, and reading must go on during Shutdown for requests waiting on a
+// full queue to finish.
+//
+// If ctx is done first, the remaining requests are failed so that Telegram
+// sends their updates again, and ctx.Err() is returned. The contexts passed
+// to Handler are then cancelled, and Shutdown waits for it to return.
+func (s *WebhookServer) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	srv := s.server
+	s.mu.Unlock()
+
+	var err error
+	if srv != nil {
+		err = srv.Shutdown(ctx)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		close(s.closing)
+		<-done
+
+		err = ctx.Err()
+	}
+
+	close(s.updates)
+
+	return err
+}
+
+func writeWebhookError(w http.ResponseWriter, status int, err error) {
+	errMsg, _ := json.Marshal(map[string]string{"error": err.Error()})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(errMsg)
+}
+
+// webhookReply holds the method a webhook request is answered with.
+type webhookReply struct {
+	mu       sync.Mutex
+	c        Chattable
+	finished bool
+}
+
+type webhookReplyKey struct{}
+
+// set stores c as the reply unless there already is one or the request was
+// answered.
+func (r *webhookReply) set(c Chattable) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.c != nil || r.finished {
+		return false
+	}
+
+	r.c = c
+
+	return true
+}
+
+// finish returns the reply and prevents setting one afterwards.
+func (r *webhookReply) finish() Chattable {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.finished = true
+
+	return r.c
+}
+
+// Reply sends c in response to the update being handled.
+//
+// When the update is handled by a WebhookServer with a Handler, the first
+// reply without files to upload is written into the webhook response,
+// saving a request. Its result is not known, and nil is returned. Any other
+// reply is sent with bot.RequestContext.
+func Reply(ctx context.Context, bot *BotAPI, c Chattable) error {
+	if reply, ok := ctx.Value(webhookReplyKey{}).(*webhookReply); ok {
+		uploads := false
+		if t, ok := c.(Fileable); ok {
+			uploads = hasFilesNeedingUpload(t.files())
+		}
+
+		if !uploads {
+			// Report invalid replies now, as the response is written later.
+			if _, err := c.params(); err != nil {
+				return err
+			}
+
+			if reply.set(c) {
+				return nil
+			}
+		}
+	}
+
+	_, err := bot.RequestContext(ctx, c)
+
+	return err
+}
+
+// ReplyHandler creates a HandlerFunc from a function returning the method to
+// answer the update with, which is sent with Reply. The reply may be nil.
+func ReplyHandler(handler func(ctx context.Context, bot *BotAPI, update Update) (Chattable, error)) HandlerFunc {
+	return func(ctx context.Context, bot *BotAPI, update Update) error {
+		c, err := handler(ctx, bot, update)
+		if err != nil || c == nil {
+			return err
+		}
+
+		return Reply(ctx, bot, c)
+	}
+}
@@ -0,0 +1,235 @@
+package tgbotapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func postUpdate(server http.Handler, token, body string) int {
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+	if token != "" {
+		req.Header.Set(secretTokenHeader, token)
+	}
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	return rec.Code
+}
+
+func TestWebhookServerValidatesRequests(t *testing.T) {
+	server := NewWebhookServer(&BotAPI{Buffer: 1}, "secret")
+	server.MaxBodySize = 64
+
+	if code := postUpdate(server, "", `{"update_id":1}`); code != http.StatusUnauthorized {
+		t.Errorf("missing token: expected 401, got %d", code)
+	}
+	if code := postUpdate(server, "wrong", `{"update_id":1}`); code != http.StatusUnauthorized {
+		t.Errorf("wrong token: expected 401, got %d", code)
+	}
+	if code := postUpdate(server, "secret", `{"update_id":1,"message":{"text":"`+strings.Repeat("a", 64)+`"}}`); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("large body: expected 413, got %d", code)
+	}
+	if code := postUpdate(server, "secret", `not json`); code != http.StatusBadRequest {
+		t.Errorf("bad body: expected 400, got %d", code)
+	}
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/webhook", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET: expected 405, got %d", rec.Code)
+	}
+
+	if code := postUpdate(server, "secret", `{"update_id":1}`); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+
+	update := <-server.Updates()
+	if update.UpdateID != 1 {
+		t.Fatalf("unexpected update %d", update.UpdateID)
+	}
+}
+
+func TestWebhookServerShutdownDrains(t *testing.T) {
+	server := NewWebhookServer(&BotAPI{Buffer: 1}, "")
+
+	if code := postUpdate(server, "", `{"update_id":1}`); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+
+	// The queue is full, so this request waits until the first update is read.
+	waiting := make(chan int)
+	go func() {
+		waiting <- postUpdate(server, "", `{"update_id":2}`)
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	shutdown := make(chan error)
+	go func() {
+		shutdown <- server.Shutdown(context.Background())
+	}()
+
+	var ids []int
+	for update := range server.Updates() {
+		ids = append(ids, update.UpdateID)
+	}
+
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Fatalf("unexpected updates %v", ids)
+	}
+	if code := <-waiting; code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if err := <-shutdown; err != nil {
+		t.Fatal(err)
+	}
+
+	if code := postUpdate(server, "", `{"update_id":3}`); code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 after shutdown, got %d", code)
+	}
+}
+
+func TestWebhookServerShutdownTimeout(t *testing.T) {
+	server := NewWebhookServer(&BotAPI{Buffer: 1}, "")
+
+	postUpdate(server, "", `{"update_id":1}`)
+
+	waiting := make(chan int)
+	go func() {
+		waiting <- postUpdate(server, "", `{"update_id":2}`)
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if code := <-waiting; code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 for the unqueued update, got %d", code)
+	}
+}
+
+func TestWebhookServerShutdownCancelsHandlers(t *testing.T) {
+	server := NewWebhookServer(&BotAPI{}, "")
+
+	started := make(chan struct{})
+	server.Handler = func(ctx context.Context, update Update) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	waiting := make(chan int)
+	go func() {
+		waiting <- postUpdate(server, "", `{"update_id":1}`)
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	shutdown := make(chan error)
+	go func() {
+		shutdown <- server.Shutdown(ctx)
+	}()
+
+	select {
+	case err := <-shutdown:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("expected deadline exceeded, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Shutdown is blocked by the handler")
+	}
+	if code := <-waiting; code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 for the cancelled handler, got %d", code)
+	}
+}
+
+func TestWebhookServerListenAndServe(t *testing.T) {
+	server := NewWebhookServer(&BotAPI{Buffer: 1}, "")
+
+	served := make(chan error)
+	go func() {
+		served <- server.ListenAndServe("127.0.0.1:0")
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	if err := server.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		t.Fatalf("expected ErrServerClosed, got %v", err)
+	}
+}
+
+func TestWebhookServerReply(t *testing.T) {
+	var methods []string
+	bot := newLocalBot(t, func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:])
+		_, _ = w.Write([]byte(`{"ok":true,"result":true}`))
+	})
+
+	dispatcher := NewDispatcher(bot)
+	dispatcher.HandleUpdateType(UpdateTypeCallbackQuery, ReplyHandler(func(ctx context.Context, bot *BotAPI, update Update) (Chattable, error) {
+		return NewCallback(update.CallbackQuery.ID, "done"), nil
+	}))
+	dispatcher.HandleUpdateType(UpdateTypeMessage, func(ctx context.Context, bot *BotAPI, update Update) error {
+		if err := Reply(ctx, bot, NewDocument(int64(1), FileBytes{Name: "doc.txt", Bytes: []byte("doc")})); err != nil {
+			return err
+		}
+		if err := Reply(ctx, bot, NewMessage(int64(1), "first")); err != nil {
+			return err
+		}
+		return Reply(ctx, bot, NewMessage(int64(1), "second"))
+	})
+
+	server := NewWebhookServer(bot, "")
+	server.Handler = dispatcher.Dispatch
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(`{"update_id":1,"callback_query":{"id":"42","from":{"id":1}}}`))
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	if body := rec.Body.String(); !strings.Contains(body, "method=answerCallbackQuery") || !strings.Contains(body, "callback_query_id=42") {
+		t.Fatalf("unexpected reply %q", body)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(`{"update_id":2,"message":{"message_id":1,"date":0,"chat":{"id":1,"type":"private"},"text":"hi"}}`))
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	if body := rec.Body.String(); !strings.Contains(body, "method=sendMessage") || !strings.Contains(body, "text=first") {
+		t.Fatalf("unexpected reply %q", body)
+	}
+	if len(methods) != 2 || methods[0] != "sendDocument" || methods[1] != "sendMessage" {
+		t.Fatalf("unexpected requests %v", methods)
+	}
+}
+
+func TestWebhookServerReplyFileID(t *testing.T) {
+	bot := newLocalBot(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+	})
+
+	server := NewWebhookServer(bot, "")
+	server.Handler = func(ctx context.Context, update Update) error {
+		return Reply(ctx, bot, NewPhoto(int64(1), FileID("photo-id")))
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(`{"update_id":1,"message":{"message_id":1,"date":0,"chat":{"id":1,"type":"private"},"text":"hi"}}`))
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	body := rec.Body.String()
+	if !strings.Contains(body, "method=sendPhoto") || !strings.Contains(body, "photo=photo-id") {
+		t.Fatalf("unexpected reply %q", body)
+	}
+}
@@ -0,0 +1,240 @@
+package tgbotapi
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrWorkerPoolStopped is returned when submitting to a stopped WorkerPool.
+var ErrWorkerPoolStopped = errors.New("worker pool is stopped")
+
+// KeyFunc returns the key used to order updates in a WorkerPool. Updates with
+// the same key are handled one at a time in the order they were submitted.
+// If ok is false, the update is not ordered relative to any other update.
+type KeyFunc func(update Update) (key int64, ok bool)
+
+// KeyByChat orders updates by the chat they came from.
+func KeyByChat(update Update) (int64, bool) {
+	if chat := update.FromChat(); chat != nil {
+		return chat.ID, true
+	}
+
+	return 0, false
+}
+
+// KeyByUser orders updates by the user who sent them.
+func KeyByUser(update Update) (int64, bool) {
+	if user := update.SentFrom(); user != nil {
+		return user.ID, true
+	}
+
+	return 0, false
+}
+
+// KeyByChatOrUser orders updates by chat, falling back to the sender for
+// updates without a chat such as inline queries.
+func KeyByChatOrUser(update Update) (int64, bool) {
+	if key, ok := KeyByChat(update); ok {
+		return key, true
+	}
+
+	return KeyByUser(update)
+}
+
+// updateQueue holds the pending updates for one key. It is either waiting in
+// the ready channel or being handled by a worker while it is in the queues
+// map.
+type updateQueue struct {
+	key     int64
+	keyed   bool
+	updates []Update
+}
+
+// WorkerPool handles updates concurrently while keeping updates with the same
+// key in order.
+//
+// At most queueSize updates are pending at once; Submit blocks until there is
+// room, which applies backpressure to the source of updates.
+type WorkerPool struct {
+	// Key selects the key updates are ordered by. Defaults to KeyByChatOrUser.
+	Key KeyFunc
+	// ErrorHandler is called with errors returned by the handler. If nil,
+	// errors are logged.
+	ErrorHandler func(update Update, err error)
+	// Ack, if not nil, is called once an update was handled, even if the
+	// handler failed. Set it to BotAPI.AckUpdate when the bot has an
+	// OffsetStore or an UpdateIDStore.
+	Ack func(update Update) error
+
+	workers int
+	handler func(ctx context.Context, update Update) error
+
+	slots chan struct{}
+	ready chan *updateQueue
+
+	mu      sync.Mutex
+	ctx     context.Context
+	queues  map[int64]*updateQueue
+	stopped bool
+	pending sync.WaitGroup
+	running sync.WaitGroup
+}
+
+// NewWorkerPool creates a WorkerPool that calls handler from the given number
+// of workers with at most queueSize updates pending.
+//
+// A Dispatcher can be used as handler by passing its Dispatch method.
+func NewWorkerPool(workers, queueSize int, handler func(ctx context.Context, update Update) error) *WorkerPool {
+	if workers < 1 {
+		workers = 1
+	}
+	if queueSize < 1 {
+		queueSize = 1
+	}
+
+	return &WorkerPool{
+		Key:     KeyByChatOrUser,
+		workers: workers,
+		handler: handler,
+		slots:   make(chan struct{}, queueSize),
+		ready:   make(chan *updateQueue, queueSize),
+		queues:  make(map[int64]*updateQueue),
+	}
+}
+
+// Start starts the workers. Handlers receive ctx, and once it is done any
+// updates still queued are dropped instead of handled.
+func (p *WorkerPool) Start(ctx context.Context) {
+	p.mu.Lock()
+	p.ctx = ctx
+	p.mu.Unlock()
+
+	for i := 0; i < p.workers; i++ {
+		p.running.Add(1)
+		go p.work()
+	}
+}
+
+// Submit queues an update, blocking while the pool is full.
+func (p *WorkerPool) Submit(ctx context.Context, update Update) error {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stopped {
+		<-p.slots
+		return ErrWorkerPoolStopped
+	}
+
+	p.pending.Add(1)
+
+	key, keyed := p.Key(update)
+	if keyed {
+		if q, ok := p.queues[key]; ok {
+			q.updates = append(q.updates, update)
+			return nil
+		}
+	}
+
+	q := &updateQueue{key: key, keyed: keyed, updates: []Update{update}}
+	if keyed {
+		p.queues[key] = q
+	}
+
+	p.ready <- q
+
+	return nil
+}
+
+// Stop waits for all submitted updates to be handled and stops the workers.
+// It must not be called concurrently with Submit.
+func (p *WorkerPool) Stop() {
+	p.mu.Lock()
+	if p.stopped {
+		p.mu.Unlock()
+		return
+	}
+	p.stopped = true
+	p.mu.Unlock()
+
+	p.pending.Wait()
+	close(p.ready)
+	p.running.Wait()
+}
+
+// Run starts the pool, submits updates from the channel until it is closed or
+// ctx is done, then stops the pool.
+func (p *WorkerPool) Run(ctx context.Context, updates UpdatesChannel) {
+	p.Start(ctx)
+	defer p.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case update, ok := <-updates:
+			if !ok {
+				return
+			}
+
+			if err := p.Submit(ctx, update); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (p *WorkerPool) work() {
+	defer p.running.Done()
+
+	p.mu.Lock()
+	ctx := p.ctx
+	p.mu.Unlock()
+
+	for q := range p.ready {
+		p.mu.Lock()
+		update := q.updates[0]
+		q.updates = q.updates[1:]
+		p.mu.Unlock()
+
+		if ctx.Err() == nil {
+			if err := p.handler(ctx, update); err != nil {
+				p.handleError(update, err)
+			}
+
+			if p.Ack != nil {
+				if err := p.Ack(update); err != nil {
+					log.Printf("Failed to acknowledge update %d: %v", update.UpdateID, err)
+				}
+			}
+		}
+
+		// Requeue the key behind the others so a busy chat can't starve the
+		// rest, or forget it once it has nothing left.
+		p.mu.Lock()
+		if len(q.updates) > 0 {
+			p.ready <- q
+		} else if q.keyed {
+			delete(p.queues, q.key)
+		}
+		p.mu.Unlock()
+
+		<-p.slots
+		p.pending.Done()
+	}
+}
+
+func (p *WorkerPool) handleError(update Update, err error) {
+	if p.ErrorHandler != nil {
+		p.ErrorHandler(update, err)
+		return
+	}
+
+	log.Printf("Failed to handle update %d: %v", update.UpdateID, err)
+}
@@ -0,0 +1,106 @@
+package tgbotapi
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func chatUpdate(id int, chatID int64) Update {
+	return Update{UpdateID: id, Message: &Message{Chat: &Chat{ID: chatID}}}
+}
+
+func TestWorkerPoolOrdersByChat(t *testing.T) {
+	var mu sync.Mutex
+	seen := map[int64][]int{}
+
+	pool := NewWorkerPool(4, 16, func(ctx context.Context, update Update) error {
+		chatID := update.Message.Chat.ID
+		if chatID == 1 {
+			// A slow chat must not reorder its own updates.
+			time.Sleep(5 * time.Millisecond)
+		}
+
+		mu.Lock()
+		seen[chatID] = append(seen[chatID], update.UpdateID)
+		mu.Unlock()
+
+		return nil
+	})
+
+	ch := make(chan Update, 20)
+	for i := 0; i < 20; i++ {
+		ch <- chatUpdate(i, int64(i%2+1))
+	}
+	close(ch)
+
+	pool.Run(context.Background(), ch)
+
+	for chatID, ids := range seen {
+		if len(ids) != 10 {
+			t.Fatalf("chat %d: expected 10 updates, got %d", chatID, len(ids))
+		}
+		for i := 1; i < len(ids); i++ {
+			if ids[i] < ids[i-1] {
+				t.Fatalf("chat %d: updates out of order: %v", chatID, ids)
+			}
+		}
+	}
+}
+
+func TestWorkerPoolConcurrentChats(t *testing.T) {
+	started := make(chan int64, 2)
+	release := make(chan struct{})
+
+	pool := NewWorkerPool(2, 4, func(ctx context.Context, update Update) error {
+		started <- update.Message.Chat.ID
+		<-release
+		return nil
+	})
+	pool.Start(context.Background())
+
+	ctx := context.Background()
+	_ = pool.Submit(ctx, chatUpdate(1, 1))
+	_ = pool.Submit(ctx, chatUpdate(2, 2))
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatal("updates from different chats were not handled concurrently")
+		}
+	}
+
+	close(release)
+	pool.Stop()
+}
+
+func TestWorkerPoolBackpressure(t *testing.T) {
+	release := make(chan struct{})
+
+	pool := NewWorkerPool(1, 1, func(ctx context.Context, update Update) error {
+		<-release
+		return nil
+	})
+	pool.Start(context.Background())
+
+	if err := pool.Submit(context.Background(), chatUpdate(1, 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := pool.Submit(ctx, chatUpdate(2, 1)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected submit to block until deadline, got %v", err)
+	}
+
+	close(release)
+	pool.Stop()
+
+	if err := pool.Submit(context.Background(), chatUpdate(3, 1)); !errors.Is(err, ErrWorkerPoolStopped) {
+		t.Fatalf("expected ErrWorkerPoolStopped, got %v", err)
+	}
+}